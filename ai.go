@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// ErrNoLegalMoves is returned by the engine when the side to move has no
+// legal moves, i.e. the game is already over
+var ErrNoLegalMoves = errors.New("no legal moves")
+
+// seededRand is the source of every random choice in play, such as engine
+// tie-breaks and the random move source, seeded from -seed so that games
+// can be reproduced
+var seededRand *rand.Rand
+
+// mateScore is the score of delivering checkmate; it is reduced by the
+// number of plies needed so that faster mates are preferred
+const mateScore = 100000
+
+// FormatScore renders a search score for the side to move in pawns, or as
+// "mate in N" / "mated in N" when the search found a forced mate
+func FormatScore(score int) string {
+	if abs(score) > mateScore-1000 {
+		moves := (mateScore - abs(score) + 1) / 2
+		if score > 0 {
+			return fmt.Sprintf("mate in %d", moves)
+		}
+		return fmt.Sprintf("mated in %d", moves)
+	}
+	return fmt.Sprintf("%+.2f", float64(score)/100)
+}
+
+// BestMove searches depth plies ahead with alpha-beta pruning, scoring
+// positions with eval, and returns the best move for the side to move. It
+// returns ErrNoLegalMoves when the side to move is checkmated or
+// stalemated, since there is nothing to choose from.
+func (b *Board) BestMove(depth int, eval Evaluator) (Move, error) {
+	pv, _, err := b.SearchWith(depth, eval)
+	if err != nil {
+		return Move{}, err
+	}
+	return pv[0], nil
+}
+
+// Search searches depth plies ahead and returns the principal variation,
+// the best line for both sides, along with its score for the side to move.
+// Positions are scored with the evaluator chosen by -evaluator.
+func (b *Board) Search(depth int) ([]Move, int, error) {
+	return b.SearchWith(depth, evaluator)
+}
+
+// SearchWith is Search scoring positions with eval. When several moves
+// share the best score, one of them is picked with seededRand, so games
+// vary unless -seed fixes them; without it the first in move order wins.
+func (b *Board) SearchWith(depth int, eval Evaluator) ([]Move, int, error) {
+	moves := b.LegalMoves(b.ToMove())
+	if len(moves) == 0 {
+		return nil, 0, ErrNoLegalMoves
+	}
+	b.nodes = 1
+	OrderMoves(moves)
+
+	// Each move is searched with a bound one below the best score so far,
+	// so that moves scoring the same get their exact score, not a cutoff
+	best := -mateScore - 1
+	var lines [][]Move
+	for _, move := range moves {
+		var line []Move
+		b.makeMove(move)
+		score := -b.negamax(eval, max(depth, 1)-1, 1, -mateScore-1, -(best - 1), &line)
+		b.undoMove(move)
+		if score > best {
+			best, lines = score, nil
+		}
+		if score == best {
+			lines = append(lines, append([]Move{move}, line...))
+		}
+	}
+
+	if seededRand == nil {
+		return lines[0], best, nil
+	}
+	return lines[seededRand.Intn(len(lines))], best, nil
+}
+
+// NodesSearched returns the number of positions visited by the last search
+func (b *Board) NodesSearched() int {
+	return b.nodes
+}
+
+// negamax returns the score of the position from the side to move's point
+// of view and stores the best line found in pv. ply is the distance from
+// the root, used to prefer faster mates.
+func (b *Board) negamax(eval Evaluator, depth, ply, alpha, beta int, pv *[]Move) int {
+	b.nodes++
+	player := b.ToMove()
+	moves := b.LegalMoves(player)
+	if len(moves) == 0 {
+		if b.IsInCheck(player) {
+			return -mateScore + ply
+		}
+		return 0
+	}
+	// Draws the defending side can steer for, such as trading down to a
+	// bare king or running out the fifty-move clock
+	if ply > 0 && (b.Rules().FiftyMove && b.halfMoveClock >= 100 || b.IsInsufficientMaterial() || b.IsWrongBishopDraw()) {
+		return 0
+	}
+	if depth <= 0 {
+		return eval.Evaluate(b, player)
+	}
+
+	OrderMoves(moves)
+	for _, move := range moves {
+		var line []Move
+		b.makeMove(move)
+		score := -b.negamax(eval, depth-1, ply+1, -beta, -alpha, &line)
+		b.undoMove(move)
+		if score >= beta {
+			return beta
+		}
+		// The first move always sets the line so the root never returns empty
+		if score > alpha || len(*pv) == 0 {
+			alpha = max(alpha, score)
+			*pv = append([]Move{move}, line...)
+		}
+	}
+	return alpha
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestStylesChooseDifferentMoves(t *testing.T) {
+	t.Cleanup(func() {
+		evalWeights = DefaultEvalWeights
+		seededRand = nil
+	})
+	// The defensive style would rather take the knight beside its king
+	// than promote
+	fen := "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8"
+	want := map[string]string{"balanced": "dxc8=Q", "defensive": "Kxf2"}
+	for style, san := range want {
+		evalWeights = evalStyles[style]
+		seededRand = rand.New(rand.NewSource(1))
+		board, err := BoardFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		move, err := board.BestMove(2, DefaultEvaluator{})
+		if err != nil {
+			t.Fatalf("%s: %v", style, err)
+		}
+		if got := board.SAN(move); got != san {
+			t.Errorf("style %s played %s, want %s", style, got, san)
+		}
+	}
+}
+
+func TestCustomEvaluatorChangesMove(t *testing.T) {
+	// Without a seed, ties go to the first move in order, a3 here
+	board := NewBoard()
+	move, err := board.BestMove(1, DefaultEvaluator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := board.SAN(move); got != "a3" {
+		t.Fatalf("default evaluator played %s, want a3", got)
+	}
+
+	// An evaluator that only cares about a white pawn on e4
+	e4 := EvaluatorFunc(func(b *Board, player Player) int {
+		score := 0
+		if piece := b.squares[4][4]; piece != nil && piece.Type == Pawn && piece.Player == White {
+			score = 100
+		}
+		if player == Black {
+			return -score
+		}
+		return score
+	})
+	move, err = board.BestMove(1, e4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := board.SAN(move); got != "e4" {
+		t.Errorf("custom evaluator played %s, want e4", got)
+	}
+}
+
+// playSeededGame plays the engine against itself from the start for up to
+// plies moves with seededRand seeded with seed, and returns the moves in SAN
+func playSeededGame(t *testing.T, seed int64, plies int) []string {
+	t.Helper()
+	seededRand = rand.New(rand.NewSource(seed))
+	g := NewGame()
+	engine := &AISource{Depth: 1}
+	var moves []string
+	for len(moves) < plies && !g.Result().IsOver() {
+		move, err := engine.NextMove(g)
+		if err != nil {
+			t.Fatalf("seed %d, after %v: %v", seed, moves, err)
+		}
+		moves = append(moves, g.Board.SAN(move))
+		if err := g.Play(move); err != nil {
+			t.Fatalf("seed %d, after %v: %v", seed, moves, err)
+		}
+	}
+	return moves
+}
+
+func TestSeedReproducesGame(t *testing.T) {
+	t.Cleanup(func() { seededRand = nil })
+	first := playSeededGame(t, 42, 20)
+	again := playSeededGame(t, 42, 20)
+	if !slices.Equal(first, again) {
+		t.Errorf("seed 42 played\n%v\nthen\n%v", first, again)
+	}
+	// Depth 1 leaves plenty of ties, so another seed plays another game
+	if other := playSeededGame(t, 43, 20); slices.Equal(first, other) {
+		t.Errorf("seeds 42 and 43 both played %v", first)
+	}
+}
+
+func TestAIAcceptDraw(t *testing.T) {
+	// White offers the draw and is to move; the engine plays Black
+	tests := []struct {
+		name   string
+		fen    string
+		accept bool
+	}{
+		{"engine a queen up", "3qk3/8/8/8/8/8/8/4K3 w - - 0 1", false},
+		{"engine a queen down", "4k3/8/8/8/8/8/8/3QK3 w - - 0 1", true},
+		{"level", "4k3/4p3/8/8/8/8/4P3/4K3 w - - 0 1", true},
+	}
+	engine := &AISource{Depth: 2}
+	for _, tt := range tests {
+		g, err := NewGameFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := engine.AcceptDraw(g); got != tt.accept {
+			t.Errorf("%s: AcceptDraw() = %v, want %v", tt.name, got, tt.accept)
+		}
+	}
+}
+
+func TestBestMoveWithNoLegalMoves(t *testing.T) {
+	tests := []struct{ name, fen string }{
+		{"checkmate", "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3"},
+		{"stalemate", "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1"},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		move, err := board.BestMove(3, DefaultEvaluator{})
+		if !errors.Is(err, ErrNoLegalMoves) || move != (Move{}) {
+			t.Errorf("%s: BestMove = %v, %v, want no move and ErrNoLegalMoves", tt.name, move, err)
+		}
+		g, err := NewGameFromFEN(tt.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := (&AISource{Depth: 3}).NextMove(g); !errors.Is(err, ErrNoLegalMoves) {
+			t.Errorf("%s: NextMove error %v, want ErrNoLegalMoves", tt.name, err)
+		}
+		if got := board.ToFEN(); got != tt.fen {
+			t.Errorf("%s: the search left the board as %s", tt.name, got)
+		}
+	}
+}
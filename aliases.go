@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Input aliases
+//
+// An alias replaces a whole input line before it is handled, so it can
+// stand for a command ("u" for "undo") or a move. "{rank}" in the expansion
+// becomes the back rank of the side to move, which lets "o-o" castle for
+// either side. Built-in commands always take precedence: an alias can't be
+// named after one.
+
+// defaultAliases are available unless overridden with -alias
+var defaultAliases = map[string]string{
+	"u":     "undo",
+	"o-o":   "e{rank}-g{rank}",
+	"o-o-o": "e{rank}-c{rank}",
+}
+
+// builtinCommands are the commands handled by HumanSource
+var builtinCommands = []string{
+	"quit", "resign", "draw", "help", "pgn", "history", "bench", "status", "threats", "undo",
+	"try", "replay", "pv", "see", "grade", "describe", "diff", "new", "switch", "games",
+}
+
+// ParseAliases parses alias definitions of the form "u=undo,k=e1-g1" and
+// adds them to the default aliases
+func ParseAliases(spec string) (map[string]string, error) {
+	aliases := make(map[string]string, len(defaultAliases))
+	for name, expansion := range defaultAliases {
+		aliases[name] = expansion
+	}
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		name, expansion, ok := strings.Cut(field, "=")
+		name, expansion = strings.TrimSpace(name), strings.TrimSpace(expansion)
+		if !ok || name == "" || expansion == "" {
+			return nil, fmt.Errorf("invalid alias %q (example: u=undo)", field)
+		}
+		for _, cmd := range builtinCommands {
+			if name == cmd {
+				return nil, fmt.Errorf("alias %q would shadow the built-in command", name)
+			}
+		}
+		aliases[name] = expansion
+	}
+	return aliases, nil
+}
+
+// expandAlias returns the input with an alias replaced by its expansion
+// for player, or the input unchanged if it isn't an alias
+func expandAlias(aliases map[string]string, input string, player Player) string {
+	expansion, ok := aliases[strings.ToLower(strings.TrimSpace(input))]
+	if !ok {
+		return input
+	}
+	rank := "1"
+	if player == Black {
+		rank = "8"
+	}
+	return strings.ReplaceAll(expansion, "{rank}", rank)
+}
+
+// formatPrompt fills in the "{player}" placeholder of a prompt
+func formatPrompt(prompt string, player Player) string {
+	return strings.ReplaceAll(prompt, "{player}", player.String())
+}
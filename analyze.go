@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// AnalyzeFEN loads a position and reports to w whether the side to move is
+// in check, checkmated or stalemated, followed by its legal moves in SAN.
+// It returns an error only when the FEN is invalid.
+func AnalyzeFEN(fen string, w io.Writer) error {
+	board, err := BoardFromFEN(fen)
+	if err != nil {
+		return err
+	}
+	player := board.ToMove()
+
+	var moves []string
+	for _, move := range board.LegalMoves(player) {
+		moves = append(moves, board.SAN(move))
+	}
+	sort.Strings(moves)
+
+	fmt.Fprint(w, board.DrawString(renderOptions))
+	fmt.Fprintf(w, "FEN: %s\n", board.ToFEN())
+	fmt.Fprintf(w, "To move: %s\n", player)
+	fmt.Fprintf(w, "Check: %s\n", yesNo(board.IsInCheck(player)))
+	fmt.Fprintf(w, "Checkmate: %s\n", yesNo(board.IsCheckmate(player)))
+	fmt.Fprintf(w, "Stalemate: %s\n", yesNo(board.IsStalemate(player)))
+	fmt.Fprintf(w, "Legal moves (%d):%s\n", len(moves), strings.Join(append([]string{""}, moves...), " "))
+	return nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
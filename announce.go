@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Announcer pipes every move played to an external command, such as a
+// text-to-speech tool. The command is started once and receives one line
+// per move on its stdin, containing the move in SAN (e.g. "Nf3", "O-O",
+// "Qxf7#"). Failures are reported once and then announcing stops; they
+// never end the game.
+type Announcer struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	failed bool
+}
+
+// StartAnnouncer runs command through the shell and returns an announcer
+// writing to it
+func StartAnnouncer(command string) (*Announcer, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &Announcer{cmd: cmd, stdin: stdin}, nil
+}
+
+// MoveMade sends the move to the command
+func (a *Announcer) MoveMade(g *Game, move Move) {
+	if a.failed {
+		return
+	}
+	if _, err := fmt.Fprintln(a.stdin, g.LastMoveSAN()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: announce command failed, no longer announcing moves: %v\n", err)
+		a.failed = true
+	}
+}
+
+// Close ends the command's input and waits for it to exit
+func (a *Announcer) Close() error {
+	a.stdin.Close()
+	return a.cmd.Wait()
+}
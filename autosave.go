@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PGN returns every game in PGN, in order of ID
+func (m *GameManager) PGN() string {
+	var pgn []string
+	for _, id := range m.IDs() {
+		pgn = append(pgn, m.games[id].PGN())
+	}
+	return strings.Join(pgn, "\n")
+}
+
+// writePGNFile writes pgn to path. The file is written under a temporary
+// name and renamed into place, so an interrupted save leaves the previous
+// file intact rather than a partial one.
+func writePGNFile(path, pgn string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.WriteString(pgn); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Autosave saves the games to a PGN file every Every moves, so that long
+// games survive a crash and not only a clean exit
+type Autosave struct {
+	games *GameManager
+	path  string
+	Every int // Moves between saves; 0 saves only on exit
+
+	moves int
+}
+
+// NewAutosave returns an Autosave of the games in manager to path
+func NewAutosave(manager *GameManager, path string, every int) *Autosave {
+	return &Autosave{games: manager, path: path, Every: every}
+}
+
+// MoveMade saves the games once Every moves have been played since the
+// last save
+func (a *Autosave) MoveMade(g *Game, move Move) {
+	a.moves++
+	if a.Every > 0 && a.moves%a.Every == 0 {
+		a.save()
+	}
+}
+
+// MoveUndone saves the games without the moves taken back
+func (a *Autosave) MoveUndone(g *Game) {
+	if a.Every > 0 {
+		a.save()
+	}
+}
+
+// save writes the games out in PGN. It reads their boards, so it must run
+// on the goroutine playing the games, or once they have stopped.
+func (a *Autosave) save() {
+	if err := writePGNFile(a.path, a.games.PGN()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not autosave: %v\n", err)
+	}
+}
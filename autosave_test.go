@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAutosaveEvery(t *testing.T) {
+	moves := []string{"e2-e4", "e7-e5", "g1-f3"}
+	for _, every := range []int{0, 1, 2} {
+		path := filepath.Join(t.TempDir(), "games.pgn")
+		games := NewGameManager(1)
+		autosave := NewAutosave(games, path, every)
+		g := games.Current()
+		var want string
+		for i, notation := range moves {
+			m, err := ParseCoordinateMove(notation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := g.Play(m); err != nil {
+				t.Fatalf("%s: %v", notation, err)
+			}
+			autosave.MoveMade(g, g.Moves[len(g.Moves)-1])
+			if every > 0 && (i+1)%every == 0 {
+				want = games.PGN()
+			}
+		}
+
+		saved, err := os.ReadFile(path)
+		if every == 0 {
+			if !os.IsNotExist(err) {
+				t.Errorf("every 0: saved before exit")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("every %d: %v", every, err)
+		}
+		if string(saved) != want {
+			t.Errorf("every %d: saved:\n%s\nwant:\n%s", every, saved, want)
+		}
+	}
+}
+
+// finishingSource reports when a move source abandoned by an interrupted
+// game finally returns, so that a test can wait for it
+type finishingSource struct {
+	MoveSource
+	done chan struct{}
+}
+
+func (f *finishingSource) NextMove(g *Game) (Move, error) {
+	defer close(f.done)
+	return f.MoveSource.NextMove(g)
+}
+
+func TestInterruptStopsGames(t *testing.T) {
+	// Run with -race to check an abandoned source leaves the game alone
+	// while it is saved
+	input, typing := io.Pipe()
+	t.Cleanup(func() { typing.Close() })
+	tests := []struct {
+		name    string
+		sources [2]MoveSource
+	}{
+		{"searching", [2]MoveSource{&finishingSource{&AISource{Depth: 4}, make(chan struct{})}, &AISource{Depth: 4}}},
+		{"waiting for input", [2]MoveSource{&HumanSource{scanner: bufio.NewScanner(input)}, &AISource{Depth: 1}}},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "games.pgn")
+		games := NewGameManager(1)
+		autosave := NewAutosave(games, path, 0)
+		games.AddListener(autosave)
+		interrupts := make(chan os.Signal, 1)
+		games.Interrupts = interrupts
+
+		interrupts <- os.Interrupt
+		games.Run(tt.sources)
+		if !games.Interrupted {
+			t.Errorf("%s: not interrupted", tt.name)
+		}
+		autosave.save()
+		if g := games.Current(); len(g.Moves) > 0 || g.Board.ToFEN() != NewBoard().ToFEN() {
+			t.Errorf("%s: game moved on to %s", tt.name, g.Board.ToFEN())
+		}
+		saved, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if want := games.PGN(); string(saved) != want {
+			t.Errorf("%s: saved:\n%s\nwant:\n%s", tt.name, saved, want)
+		}
+		if games.Wait(func() {}) {
+			t.Errorf("%s: waits after the interrupt", tt.name)
+		}
+		if f, ok := tt.sources[White].(*finishingSource); ok {
+			<-f.done
+		}
+	}
+}
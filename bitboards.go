@@ -0,0 +1,130 @@
+package main
+
+import "math/bits"
+
+// bitboards mirror the squares array as one 64-bit set per colour and piece
+// type, with bit row*8+col set when such a piece stands on that square.
+// squares stays the source of truth; the sets make attack tests and move
+// generation cheap. makeMove and undoMove keep them in step through
+// setSquare, and positions set up square by square call syncBitboards.
+type bitboards struct {
+	pieces   [2][King + 1]uint64
+	occupied [2]uint64
+}
+
+// squareIndex returns the bit number of pos in a bitboard
+func squareIndex(pos Position) int {
+	return pos.Row*8 + pos.Col
+}
+
+// Attack tables, indexed by square
+var (
+	knightAttacks [64]uint64
+	kingAttacks   [64]uint64
+	pawnAttacks   [2][64]uint64 // Squares a pawn of each colour attacks from the square
+	rayMasks      [8][64]uint64 // Squares along each of rayDirections, up to the edge
+)
+
+// rayDirections are the rook rays followed by the bishop rays
+var rayDirections = append(append([][2]int{}, rookRays...), bishopRays...)
+
+func init() {
+	mask := func(pos Position, offsets [][2]int) uint64 {
+		var set uint64
+		for _, o := range offsets {
+			if to := (Position{pos.Row + o[0], pos.Col + o[1]}); isValidPosition(to) {
+				set |= 1 << squareIndex(to)
+			}
+		}
+		return set
+	}
+	for sq := 0; sq < 64; sq++ {
+		pos := Position{sq / 8, sq % 8}
+		knightAttacks[sq] = mask(pos, knightOffsets)
+		kingAttacks[sq] = mask(pos, kingOffsets)
+		// White pawns move up the board, towards row 0
+		pawnAttacks[White][sq] = mask(pos, [][2]int{{-1, -1}, {-1, 1}})
+		pawnAttacks[Black][sq] = mask(pos, [][2]int{{1, -1}, {1, 1}})
+		for d, r := range rayDirections {
+			for to := (Position{pos.Row + r[0], pos.Col + r[1]}); isValidPosition(to); to = (Position{to.Row + r[0], to.Col + r[1]}) {
+				rayMasks[d][sq] |= 1 << squareIndex(to)
+			}
+		}
+	}
+}
+
+// lightSquares is the set of light squares, a8 and h1 among them
+const lightSquares uint64 = 0xaa55aa55aa55aa55
+
+// PieceCount returns how many pieces of type pt player has on the board.
+// The count comes from the bitboards, so it costs the same however many
+// pieces there are and stays right through captures, promotions and undos.
+func (b *Board) PieceCount(player Player, pt PieceType) int {
+	return bits.OnesCount64(b.bb.pieces[player][pt])
+}
+
+// setSquare puts piece, or nothing if it is nil, on pos and updates the
+// bitboards to match
+func (b *Board) setSquare(pos Position, piece *Piece) {
+	bit := uint64(1) << squareIndex(pos)
+	if old := b.squares[pos.Row][pos.Col]; old != nil {
+		b.bb.pieces[old.Player][old.Type] &^= bit
+		b.bb.occupied[old.Player] &^= bit
+	}
+	b.squares[pos.Row][pos.Col] = piece
+	if piece != nil {
+		b.bb.pieces[piece.Player][piece.Type] |= bit
+		b.bb.occupied[piece.Player] |= bit
+	}
+}
+
+// syncBitboards rebuilds the bitboards from the squares array
+func (b *Board) syncBitboards() {
+	b.bb = bitboards{}
+	for sq := 0; sq < 64; sq++ {
+		if piece := b.squares[sq/8][sq%8]; piece != nil {
+			b.bb.pieces[piece.Player][piece.Type] |= 1 << sq
+			b.bb.occupied[piece.Player] |= 1 << sq
+		}
+	}
+}
+
+// rayAttacks returns the squares along ray d from sq up to and including
+// the first occupied one
+func (b *Board) rayAttacks(sq, d int) uint64 {
+	attacks := rayMasks[d][sq]
+	blockers := attacks & (b.bb.occupied[White] | b.bb.occupied[Black])
+	if blockers == 0 {
+		return attacks
+	}
+	// The nearest blocker is the lowest bit on rays towards higher squares
+	r := rayDirections[d]
+	nearest := 63 - bits.LeadingZeros64(blockers)
+	if r[0]*8+r[1] > 0 {
+		nearest = bits.TrailingZeros64(blockers)
+	}
+	return attacks &^ rayMasks[d][nearest]
+}
+
+// isSquareAttacked reports whether any of player's pieces attacks pos,
+// whether or not it could legally move there
+func (b *Board) isSquareAttacked(pos Position, player Player) bool {
+	sq := squareIndex(pos)
+	own := &b.bb.pieces[player]
+	// A pawn attacks pos when an opposing pawn on pos would attack it back
+	if knightAttacks[sq]&own[Knight] != 0 || kingAttacks[sq]&own[King] != 0 ||
+		pawnAttacks[1-player][sq]&own[Pawn] != 0 {
+		return true
+	}
+	straight, diagonal := own[Rook]|own[Queen], own[Bishop]|own[Queen]
+	for d := range rayDirections {
+		sliders := straight
+		if d >= len(rookRays) {
+			sliders = diagonal
+		}
+		if sliders != 0 && b.rayAttacks(sq, d)&sliders != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// isInCheckByScan is the reference for IsInCheck: it asks ValidateMove,
+// which already covers piece geometry and blocked paths, whether any
+// opponent piece could capture player's king
+func (b *Board) isInCheckByScan(player Player) bool {
+	kingPos := b.whiteKing
+	if player == Black {
+		kingPos = b.blackKing
+	}
+
+	// Check if any opponent's piece can capture the king
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece != nil && piece.Player != player {
+				if _, err := b.ValidateMove(Position{row, col}, kingPos, piece.Player); err == nil {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// checkBitboards reports the first square where the bitboards disagree
+// with the squares array
+func (b *Board) checkBitboards() error {
+	want := *b
+	want.syncBitboards()
+	if want.bb == b.bb {
+		return nil
+	}
+	for sq := 0; sq < 64; sq++ {
+		for player := White; player <= Black; player++ {
+			for pt := Pawn; pt <= King; pt++ {
+				bit := uint64(1) << sq
+				if b.bb.pieces[player][pt]&bit != want.bb.pieces[player][pt]&bit {
+					return fmt.Errorf("%s: bitboards out of step on %s for %s %s", b.ToFEN(), Position{sq / 8, sq % 8}, player, pt)
+				}
+			}
+		}
+	}
+	return fmt.Errorf("%s: occupancy bitboards out of step", b.ToFEN())
+}
+
+func TestBitboardsInStep(t *testing.T) {
+	walkPositions(t, func(b *Board) bool {
+		if err := b.checkBitboards(); err != nil {
+			t.Error(err)
+			return false
+		}
+		for _, player := range []Player{White, Black} {
+			if got, want := b.IsInCheck(player), b.isInCheckByScan(player); got != want {
+				t.Errorf("%s: IsInCheck(%s) = %v, the scan says %v", b.ToFEN(), player, got, want)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestIsSquareAttacked(t *testing.T) {
+	tests := []struct {
+		fen    string
+		square string
+		by     Player
+		want   bool
+	}{
+		{"4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "a8", White, true},     // Rook along the file
+		{"4k3/8/8/8/8/8/p7/R3K3 w - - 0 1", "a8", White, false},   // Blocked by a pawn
+		{"4k3/8/8/8/8/8/p7/R3K3 w - - 0 1", "a2", White, true},    // The blocker itself is attacked
+		{"4k3/8/8/8/8/8/8/4K2B w - - 0 1", "a8", White, true},     // Bishop along the long diagonal
+		{"4k3/8/8/8/3N4/8/8/4K3 w - - 0 1", "e6", White, true},    // Knight
+		{"4k3/8/8/8/3N4/8/8/4K3 w - - 0 1", "d5", White, false},   // Not a knight's square
+		{"4k3/8/8/8/8/8/3P4/4K3 w - - 0 1", "e3", White, true},    // White pawns attack up the board
+		{"4k3/8/8/8/8/8/3P4/4K3 w - - 0 1", "d3", White, false},   // but not straight ahead
+		{"4k3/3p4/8/8/8/8/8/4K3 w - - 0 1", "c6", Black, true},    // Black pawns attack down
+		{"4k3/3p4/8/8/8/8/8/4K3 w - - 0 1", "c8", Black, false},   // not backwards
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", "f2", White, true},      // King
+		{"4k3/8/8/8/8/8/8/Q3K3 w - - 0 1", "h8", White, true},     // Queen along the long diagonal
+		{"4k3/8/8/8/8/8/8/Q3K3 w - - 0 1", "d1", White, true},     // Queen along the rank
+		{"4k3/8/8/8/8/8/8/Q3K3 w - - 0 1", "g1", White, false},    // but not past its own king
+		{"7k/8/8/8/8/8/8/K6q w - - 0 1", "b1", Black, true},       // Black queen along the first rank
+		{"7k/8/8/8/8/8/8/K5Nq w - - 0 1", "b1", Black, false},     // blocked by a knight
+		{"7k/8/8/8/8/8/8/K5Nq w - - 0 1", "g1", Black, true},      // which it attacks
+		{"r3k3/8/8/8/8/8/8/4K3 w - - 0 1", "a1", Black, true},     // Black rook down the file
+		{"r3k3/8/8/8/8/8/8/4K3 w - - 0 1", "h8", Black, false},    // past its king
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", "e5", White, false},     // Nothing in reach
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", "d7", Black, true},      // Black king
+		{"4k3/8/8/8/8/8/1b6/4K3 w - - 0 1", "h8", Black, true},    // Bishop up the long diagonal
+		{"4k3/8/8/8/3P4/8/1b6/4K3 w - - 0 1", "h8", Black, false}, // blocked on d4
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		pos, err := ParseSquare(tt.square)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := board.isSquareAttacked(pos, tt.by); got != tt.want {
+			t.Errorf("%s: isSquareAttacked(%s, %s) = %v, want %v", tt.fen, tt.square, tt.by, got, tt.want)
+		}
+	}
+}
+
+func TestPieceCountThroughMovesAndUndos(t *testing.T) {
+	// counts are White's pawns and queens and Black's pawns and rooks
+	type counts [4]int
+	countsOf := func(b *Board) counts {
+		return counts{b.PieceCount(White, Pawn), b.PieceCount(White, Queen), b.PieceCount(Black, Pawn), b.PieceCount(Black, Rook)}
+	}
+	board, err := BoardFromFEN("r3k3/1P6/8/3pP3/8/8/8/4K3 w - d6 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []struct {
+		move string
+		want counts
+	}{
+		{"e5-d6", counts{2, 0, 0, 1}},  // En passant
+		{"e8-d7", counts{2, 0, 0, 1}},  // Nothing captured
+		{"b7-a8q", counts{1, 1, 0, 0}}, // Capturing promotion
+		{"d7-d6", counts{0, 1, 0, 0}},  // The king captures
+	}
+	history := []counts{countsOf(board)}
+	if want := (counts{2, 0, 1, 1}); history[0] != want {
+		t.Fatalf("start: counts %v, want %v", history[0], want)
+	}
+	var moves []Move
+	for _, step := range steps {
+		moves = append(moves, playMove(t, board, step.move))
+		if got := countsOf(board); got != step.want {
+			t.Errorf("after %s: counts %v, want %v", step.move, got, step.want)
+		}
+		history = append(history, step.want)
+	}
+	for i := len(moves) - 1; i >= 0; i-- {
+		board.undoMove(moves[i])
+		if got := countsOf(board); got != history[i] {
+			t.Errorf("after undoing %s: counts %v, want %v", moves[i], got, history[i])
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+// CastlingRights records which castling moves are still available. Rights
+// are lost for good once the king or the rook concerned moves or the rook
+// is captured, independently of the pieces' HasMoved flags.
+type CastlingRights uint8
+
+const (
+	WhiteKingSide CastlingRights = 1 << iota
+	WhiteQueenSide
+	BlackKingSide
+	BlackQueenSide
+
+	AllCastlingRights = WhiteKingSide | WhiteQueenSide | BlackKingSide | BlackQueenSide
+)
+
+// castlingRight returns the right for player castling on the given wing
+func castlingRight(player Player, kingSide bool) CastlingRights {
+	switch {
+	case player == White && kingSide:
+		return WhiteKingSide
+	case player == White:
+		return WhiteQueenSide
+	case kingSide:
+		return BlackKingSide
+	default:
+		return BlackQueenSide
+	}
+}
+
+// CanCastle reports whether player still has the right to castle on the
+// given wing. It doesn't check that castling is playable right now.
+func (b *Board) CanCastle(player Player, kingSide bool) bool {
+	return b.castling&castlingRight(player, kingSide) != 0
+}
+
+// deriveCastlingRights grants the rights implied by unmoved kings and rooks
+// on their starting squares, for positions set up without a move history
+func (b *Board) deriveCastlingRights() {
+	b.castling = 0
+	for _, player := range []Player{White, Black} {
+		row := 7
+		if player == Black {
+			row = 0
+		}
+		king := b.squares[row][4]
+		if king == nil || king.Type != King || king.Player != player || king.HasMoved {
+			continue
+		}
+		for _, kingSide := range []bool{true, false} {
+			rookCol := 7
+			if !kingSide {
+				rookCol = 0
+			}
+			rook := b.squares[row][rookCol]
+			if rook != nil && rook.Type == Rook && rook.Player == player && !rook.HasMoved {
+				b.castling |= castlingRight(player, kingSide)
+			}
+		}
+	}
+}
+
+// updateCastlingRights removes the rights lost by a move: the king moving,
+// or a rook leaving or being captured on its starting corner
+func (b *Board) updateCastlingRights(move Move) {
+	if move.Piece.Type == King {
+		b.castling &^= castlingRight(move.Piece.Player, true) | castlingRight(move.Piece.Player, false)
+	}
+	for _, pos := range []Position{move.From, move.To} {
+		switch pos {
+		case Position{7, 7}:
+			b.castling &^= WhiteKingSide
+		case Position{7, 0}:
+			b.castling &^= WhiteQueenSide
+		case Position{0, 7}:
+			b.castling &^= BlackKingSide
+		case Position{0, 0}:
+			b.castling &^= BlackQueenSide
+		}
+	}
+}
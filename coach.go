@@ -0,0 +1,30 @@
+package main
+
+import "fmt"
+
+// coachNotes are the teaching notes shown the first time each piece type is
+// captured in a game
+var coachNotes = map[PieceType]string{
+	Pawn:   "First pawn captured: the pawn structure has changed for good, so check which files are opening.",
+	Knight: "First knight traded: the minor pieces are coming off, count who has the better ones left.",
+	Bishop: "First bishop traded: a side without its bishop pair is weaker on the squares of that color.",
+	Rook:   "First rook traded: with fewer heavy pieces, the kings become safer and more useful.",
+	Queen:  "First queen traded: the game heads for an endgame, so activate your king.",
+}
+
+// Coach is a listener that prints a short note the first time each piece
+// type is captured in a game
+type Coach struct {
+	games *GameManager
+}
+
+// MoveMade notes the first capture of the captured piece's type
+func (c *Coach) MoveMade(g *Game, move Move) {
+	if move.Captured == nil || g.captureSeen[move.Captured.Type] {
+		return
+	}
+	g.captureSeen[move.Captured.Type] = true
+	if note, ok := coachNotes[move.Captured.Type]; ok {
+		c.games.Notify(fmt.Sprintf("Coach: %s", note))
+	}
+}
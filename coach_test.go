@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCoachAfterUndo(t *testing.T) {
+	games := NewGameManager(1)
+	coach := &Coach{games: games}
+	g := games.Current()
+	play := func(notation string) {
+		t.Helper()
+		m, err := ParseCoordinateMove(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Play(m); err != nil {
+			t.Fatalf("%s: %v", notation, err)
+		}
+		coach.MoveMade(g, g.Moves[len(g.Moves)-1])
+	}
+	noted := func() bool {
+		t.Helper()
+		defer func() { games.notice = "" }()
+		return strings.Contains(games.notice, coachNotes[Pawn])
+	}
+
+	for _, notation := range []string{"e2-e4", "d7-d5", "e4-d5"} {
+		play(notation)
+	}
+	if !noted() {
+		t.Fatal("first pawn capture not noted")
+	}
+
+	// Taking the capture back makes the next one the first again
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	play("e4-d5")
+	if !noted() {
+		t.Error("pawn capture played again after undo not noted")
+	}
+
+	// Undoing a later capture keeps the earlier one seen
+	play("c7-c6")
+	play("d5-c6")
+	if noted() {
+		t.Error("second pawn capture noted")
+	}
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	play("d5-c6")
+	if noted() {
+		t.Error("second pawn capture noted after undo")
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Describe returns the position as one plain sentence per rank, from rank 8
+// down to rank 1, for screen readers and text-to-speech, e.g.
+//
+//	Rank 8: black rook a8, black knight b8, empty c8, ...
+//
+// With occupiedOnly, empty squares are left out and empty ranks read
+// "Rank 5: empty".
+func (b *Board) Describe(occupiedOnly bool) []string {
+	lines := make([]string, 0, 8)
+	for row := 0; row < 8; row++ {
+		var squares []string
+		for col := 0; col < 8; col++ {
+			pos := Position{row, col}
+			piece := b.squares[row][col]
+			switch {
+			case piece != nil:
+				squares = append(squares, fmt.Sprintf("%s %s %s", strings.ToLower(piece.Player.String()), piece.Type, pos))
+			case !occupiedOnly:
+				squares = append(squares, "empty "+pos.String())
+			}
+		}
+		if len(squares) == 0 {
+			squares = []string{"empty"}
+		}
+		lines = append(lines, fmt.Sprintf("Rank %d: %s", 8-row, strings.Join(squares, ", ")))
+	}
+	return lines
+}
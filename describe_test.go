@@ -0,0 +1,39 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	board, err := BoardFromFEN("4k3/8/8/8/8/8/4P3/4K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := []string{
+		"Rank 8: empty a8, empty b8, empty c8, empty d8, black king e8, empty f8, empty g8, empty h8",
+		"Rank 7: empty a7, empty b7, empty c7, empty d7, empty e7, empty f7, empty g7, empty h7",
+		"Rank 6: empty a6, empty b6, empty c6, empty d6, empty e6, empty f6, empty g6, empty h6",
+		"Rank 5: empty a5, empty b5, empty c5, empty d5, empty e5, empty f5, empty g5, empty h5",
+		"Rank 4: empty a4, empty b4, empty c4, empty d4, empty e4, empty f4, empty g4, empty h4",
+		"Rank 3: empty a3, empty b3, empty c3, empty d3, empty e3, empty f3, empty g3, empty h3",
+		"Rank 2: empty a2, empty b2, empty c2, empty d2, white pawn e2, empty f2, empty g2, empty h2",
+		"Rank 1: empty a1, empty b1, empty c1, empty d1, white king e1, empty f1, empty g1, empty h1",
+	}
+	if got := board.Describe(false); !slices.Equal(got, full) {
+		t.Errorf("Describe(false) =\n%q\nwant\n%q", got, full)
+	}
+	occupied := []string{
+		"Rank 8: black king e8",
+		"Rank 7: empty",
+		"Rank 6: empty",
+		"Rank 5: empty",
+		"Rank 4: empty",
+		"Rank 3: empty",
+		"Rank 2: white pawn e2",
+		"Rank 1: white king e1",
+	}
+	if got := board.Describe(true); !slices.Equal(got, occupied) {
+		t.Errorf("Describe(true) =\n%q\nwant\n%q", got, occupied)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffFEN compares two positions given in FEN and returns one line per
+// difference: pieces that moved, appeared or disappeared, then changes to
+// the castling rights, en passant target and side to move, e.g.
+//
+//	white pawn moved e2-e4
+//	black pawn disappeared from d5
+//	castling: KQkq -> Kkq
+//	to move: White -> Black
+//
+// A piece that left one square and stands on another with the same type
+// and owner counts as moved; anything else appears or disappears. When
+// several such pieces moved, each is paired with a square it could reach
+// in one move where possible, then with the nearest.
+func DiffFEN(fen1, fen2 string) ([]string, error) {
+	before, err := BoardFromFEN(fen1)
+	if err != nil {
+		return nil, fmt.Errorf("first FEN: %v", err)
+	}
+	after, err := BoardFromFEN(fen2)
+	if err != nil {
+		return nil, fmt.Errorf("second FEN: %v", err)
+	}
+
+	var removed, added []Position
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			old, cur := before.squares[row][col], after.squares[row][col]
+			if samePiece(old, cur) {
+				continue
+			}
+			if old != nil {
+				removed = append(removed, Position{row, col})
+			}
+			if cur != nil {
+				added = append(added, Position{row, col})
+			}
+		}
+	}
+
+	// Pair each piece that left a square with one of the same kind that
+	// arrived, best pairs first so that one piece doesn't take another's
+	// destination: a square it could reach in one move, then the nearest
+	pairs := make(map[int]int)
+	used := make([]bool, len(added))
+	for {
+		from, to, best := -1, -1, 0
+		for i, r := range removed {
+			if _, ok := pairs[i]; ok {
+				continue
+			}
+			piece := before.squares[r.Row][r.Col]
+			for j, a := range added {
+				if used[j] || !samePiece(piece, after.squares[a.Row][a.Col]) {
+					continue
+				}
+				cost := max(abs(a.Row-r.Row), abs(a.Col-r.Col))
+				if _, err := before.ValidateMove(r, a, piece.Player); err != nil {
+					cost += 8
+				}
+				if from < 0 || cost < best {
+					from, to, best = i, j, cost
+				}
+			}
+		}
+		if from < 0 {
+			break
+		}
+		pairs[from] = to
+		used[to] = true
+	}
+
+	var lines []string
+	for i, from := range removed {
+		piece := before.squares[from.Row][from.Col]
+		if j, ok := pairs[i]; ok {
+			lines = append(lines, fmt.Sprintf("%s moved %s-%s", pieceName(piece), from, added[j]))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s disappeared from %s", pieceName(piece), from))
+		}
+	}
+	for j, pos := range added {
+		if used[j] {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s appeared on %s", pieceName(after.squares[pos.Row][pos.Col]), pos))
+	}
+
+	if c1, c2 := before.castlingFEN(), after.castlingFEN(); c1 != c2 {
+		lines = append(lines, fmt.Sprintf("castling: %s -> %s", c1, c2))
+	}
+	if e1, e2 := before.enPassantFEN(), after.enPassantFEN(); e1 != e2 {
+		lines = append(lines, fmt.Sprintf("en passant: %s -> %s", e1, e2))
+	}
+	if p1, p2 := before.ToMove(), after.ToMove(); p1 != p2 {
+		lines = append(lines, fmt.Sprintf("to move: %s -> %s", p1, p2))
+	}
+	return lines, nil
+}
+
+// splitFENs splits the fields of two FENs written one after the other; the
+// second starts at the next field with a piece placement's slashes, so
+// either may leave out the move counters
+func splitFENs(fields []string) (string, string, error) {
+	for i := 1; i < len(fields); i++ {
+		if strings.Contains(fields[i], "/") {
+			return strings.Join(fields[:i], " "), strings.Join(fields[i:], " "), nil
+		}
+	}
+	return "", "", fmt.Errorf("expected two FENs (example: diff <fen1> <fen2>)")
+}
+
+// samePiece reports whether two squares hold the same kind of piece, or
+// are both empty
+func samePiece(a, b *Piece) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Type == b.Type && a.Player == b.Player
+}
+
+// pieceName names a piece for text output, e.g. "black rook"
+func pieceName(p *Piece) string {
+	return fmt.Sprintf("%s %s", strings.ToLower(p.Player.String()), p.Type)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffFEN(t *testing.T) {
+	tests := []struct {
+		name, before, after string
+		want                []string
+	}{
+		{"move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+			[]string{"white pawn moved e2-e4", "to move: White -> Black"}},
+		{"capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "4k3/8/8/3P4/8/8/8/4K3 b - - 0 1",
+			[]string{"black pawn disappeared from d5", "white pawn moved e4-d5", "to move: White -> Black"}},
+		{"castling", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "r3k2r/8/8/8/8/8/8/R4RK1 b kq - 1 1",
+			[]string{"white king moved e1-g1", "white rook moved h1-f1", "castling: KQkq -> kq", "to move: White -> Black"}},
+		{"side to move only", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", "4k3/8/8/8/8/8/8/4K3 b - - 0 1",
+			[]string{"to move: White -> Black"}},
+		{"en passant", "4k3/8/8/4P3/8/8/8/4K3 b - - 0 1", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2",
+			[]string{"black pawn appeared on d5", "en passant: - -> d6", "to move: Black -> White"}},
+		// Taking the rooks in board order would pair a8 with h8, leaving
+		// h1-a7, which no rook can play
+		{"two rooks", "R7/8/8/4k3/8/8/8/4K2R w - - 0 1", "7R/R7/8/4k3/8/8/8/4K3 w - - 0 1",
+			[]string{"white rook moved a8-a7", "white rook moved h1-h8"}},
+		// Two knights swapped sides: each takes the square it could reach
+		{"two knights", "4k3/8/8/8/8/8/8/1N2K1N1 w - - 0 1", "4k3/8/8/8/8/5N2/3N4/4K3 w - - 0 1",
+			[]string{"white knight moved b1-d2", "white knight moved g1-f3"}},
+	}
+	for _, tt := range tests {
+		got, err := DiffFEN(tt.before, tt.after)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: DiffFEN =\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := DiffFEN("4k3/8/8/8/8/8/8/4K3 w - - 0 1", "not a fen"); err == nil {
+		t.Error("DiffFEN accepted a bad second FEN")
+	}
+}
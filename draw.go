@@ -0,0 +1,153 @@
+package main
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// PositionKey identifies a position for repetition purposes: piece
+// placement, side to move, castling rights and en passant target, but not
+// the move counters. The en passant target is only in the FEN when the
+// capture is possible, so a double pawn step no one can take doesn't make
+// the position differ from the same one reached otherwise.
+func (b *Board) PositionKey() string {
+	fields := strings.Fields(b.ToFEN())
+	return strings.Join(fields[:4], " ")
+}
+
+// enPassantCapturable reports whether the side to move has a legal en
+// passant capture
+func (b *Board) enPassantCapturable() bool {
+	target, ok := b.enPassantTarget()
+	if !ok {
+		return false
+	}
+	player := b.ToMove()
+	for _, dc := range []int{-1, 1} {
+		from := Position{b.lastMove.To.Row, b.lastMove.To.Col + dc}
+		if !isValidPosition(from) {
+			continue
+		}
+		pawn := b.squares[from.Row][from.Col]
+		if pawn == nil || pawn.Type != Pawn || pawn.Player != player {
+			continue
+		}
+		if _, err := b.CheckMove(from, target, player); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// recordPosition counts an occurrence of the current position
+func (b *Board) recordPosition() {
+	if b.positionCounts == nil {
+		b.positionCounts = make(map[string]int)
+	}
+	b.positionCounts[b.PositionKey()]++
+}
+
+// forgetPosition removes an occurrence of the current position, before the
+// move that reached it is taken back
+func (b *Board) forgetPosition() {
+	key := b.PositionKey()
+	if b.positionCounts[key]--; b.positionCounts[key] <= 0 {
+		delete(b.positionCounts, key)
+	}
+}
+
+// RepetitionCount returns how many times the current position has occurred
+func (b *Board) RepetitionCount() int {
+	return b.positionCounts[b.PositionKey()]
+}
+
+// HasBareKing reports whether player has nothing left but the king
+func (b *Board) HasBareKing(player Player) bool {
+	return b.bb.occupied[player] == b.bb.pieces[player][King]
+}
+
+// HasMatingMaterial reports whether player has enough material to force
+// checkmate against a bare king: any pawn, rook or queen, a bishop with
+// another minor piece, or three minor pieces
+func (b *Board) HasMatingMaterial(player Player) bool {
+	if b.PieceCount(player, Pawn)+b.PieceCount(player, Rook)+b.PieceCount(player, Queen) > 0 {
+		return true
+	}
+	bishops := b.PieceCount(player, Bishop)
+	minors := bishops + b.PieceCount(player, Knight)
+	return (bishops >= 1 && minors >= 2) || minors >= 3
+}
+
+// IsInsufficientMaterial reports whether neither side can ever checkmate:
+// bare kings, a single minor piece, or only bishops, however many and
+// whoever owns them, all on squares of the same color. Such bishops can
+// never cover the squares of the other color, so no king can be mated.
+func (b *Board) IsInsufficientMaterial() bool {
+	for _, pt := range []PieceType{Pawn, Rook, Queen} {
+		if b.PieceCount(White, pt)+b.PieceCount(Black, pt) > 0 {
+			return false
+		}
+	}
+
+	knights := b.PieceCount(White, Knight) + b.PieceCount(Black, Knight)
+	bishops := b.bb.pieces[White][Bishop] | b.bb.pieces[Black][Bishop]
+	if knights+bits.OnesCount64(bishops) <= 1 {
+		return true
+	}
+	return knights == 0 && (bishops&lightSquares == 0 || bishops&^lightSquares == 0)
+}
+
+// IsWrongBishopDraw recognises the classic fortress of the wrong rook pawn:
+// one side has its king, one bishop and one or more pawns, all on the a- or
+// the h-file, against a bare king. When the bishop can't cover the square
+// the pawns promote on and the defending king stands on that square or
+// next to it, the defender can't be driven from the corner and the game is
+// a certain draw. Any other material, pawns on two files or the defending
+// king further away are not recognised, even if they happen to draw too.
+//
+// Unlike insufficient material this doesn't end the game, since a careless
+// defender can still walk out of the corner; the engine uses it to stop
+// searching for a win that isn't there.
+func (b *Board) IsWrongBishopDraw() bool {
+	var pieces []Position
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			if piece := b.squares[row][col]; piece != nil && piece.Type != King {
+				pieces = append(pieces, Position{row, col})
+			}
+		}
+	}
+	if len(pieces) < 2 {
+		return false
+	}
+
+	var bishop Position
+	bishops, pawnFile := 0, -1
+	strong := b.squares[pieces[0].Row][pieces[0].Col].Player
+	for _, pos := range pieces {
+		piece := b.squares[pos.Row][pos.Col]
+		switch {
+		case piece.Player != strong:
+			return false
+		case piece.Type == Bishop:
+			bishop = pos
+			bishops++
+		case piece.Type == Pawn && (pos.Col == 0 || pos.Col == 7) && (pawnFile < 0 || pawnFile == pos.Col):
+			pawnFile = pos.Col
+		default:
+			return false
+		}
+	}
+	if bishops != 1 || pawnFile < 0 {
+		return false
+	}
+
+	promotion := Position{0, pawnFile}
+	defender := b.blackKing
+	if strong == Black {
+		promotion.Row = 7
+		defender = b.whiteKing
+	}
+	sameColor := (bishop.Row+bishop.Col)%2 == (promotion.Row+promotion.Col)%2
+	return !sameColor && abs(defender.Row-promotion.Row) <= 1 && abs(defender.Col-promotion.Col) <= 1
+}
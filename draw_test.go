@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+func TestIsWrongBishopDraw(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want bool
+	}{
+		// The light-squared bishop can't drive the king from the dark h8
+		{"wrong bishop", "7k/8/8/8/8/3B3P/8/2K5 w - - 0 1", true},
+		{"wrong bishop, king beside the corner", "8/6k1/8/8/8/3B3P/7P/2K5 b - - 0 1", true},
+		{"wrong bishop for Black", "2k5/8/8/8/4b3/p7/1K6/8 w - - 0 1", true},
+		{"right bishop", "7k/8/8/8/8/4B2P/8/2K5 w - - 0 1", false},
+		{"king away from the corner", "8/8/8/4k3/8/3B3P/8/2K5 w - - 0 1", false},
+		{"knight as well", "7k/8/8/8/8/3B3P/8/2K2N2 w - - 0 1", false},
+		{"pawns on two files", "7k/8/8/8/8/P2B3P/8/2K5 w - - 0 1", false},
+		{"defender has a pawn", "7k/p7/8/8/8/3B3P/8/2K5 w - - 0 1", false},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := board.IsWrongBishopDraw(); got != tt.want {
+			t.Errorf("%s: IsWrongBishopDraw() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEnPassantRepetition(t *testing.T) {
+	// Knights go out and back after Black's double step, reaching the same
+	// placement with White to move again
+	cycle := []string{"d7-d5", "g1-f3", "g8-f6", "f3-g1", "f6-g8"}
+	tests := []struct {
+		name string
+		fen  string
+		want int
+	}{
+		// Only right after d5 could the e5 pawn take en passant, so the
+		// positions differ
+		{"en passant possible", "rnbqkbnr/pppppppp/8/4P3/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 3", 1},
+		// No pawn can take on d6, so the double step doesn't count
+		{"en passant impossible", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1", 2},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		playUntil(t, board, cycle)
+		if got := board.RepetitionCount(); got != tt.want {
+			t.Errorf("%s: position occurred %d times, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsInsufficientMaterial(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want bool
+	}{
+		{"bare kings", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", true},
+		{"single bishop", "4k3/8/8/8/8/8/8/2B1K3 w - - 0 1", true},
+		{"single knight", "4k3/8/8/8/8/8/8/1N2K3 w - - 0 1", true},
+		// c1 and f8 are both dark squares
+		{"same-coloured bishops on opposite sides", "4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", true},
+		{"same-coloured bishops on one side", "4k3/8/8/8/8/4B3/8/2B1K3 w - - 0 1", true},
+		// c1 is dark and c8 light: a mate in the corner is possible
+		{"opposite-coloured bishops on opposite sides", "2b1k3/8/8/8/8/8/8/2B1K3 w - - 0 1", false},
+		{"opposite-coloured bishops on one side", "4k3/8/8/8/8/8/8/2B1KB2 w - - 0 1", false},
+		{"bishop and knight", "4k3/8/8/8/8/8/8/1NB1K3 w - - 0 1", false},
+		{"pawn", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", false},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := board.IsInsufficientMaterial(); got != tt.want {
+			t.Errorf("%s: IsInsufficientMaterial() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
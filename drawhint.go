@@ -0,0 +1,21 @@
+package main
+
+import "fmt"
+
+// DrawHint is a listener that points out, once the halfmove clock reaches
+// After plies, that the fifty-move rule is approaching and a draw can be
+// offered. It is purely advisory.
+type DrawHint struct {
+	games *GameManager
+	After int // Plies without a capture or pawn move before the hint
+}
+
+// MoveMade gives the hint when the move brings the clock to After
+func (d *DrawHint) MoveMade(g *Game, move Move) {
+	clock := g.Board.halfMoveClock
+	if clock != d.After || !g.Rules.FiftyMove || g.Result().IsOver() {
+		return
+	}
+	d.games.Notify(fmt.Sprintf("%d moves without a capture or pawn move; the fifty-move rule lets either side claim a draw in %d more. Type 'draw' to offer one now.",
+		clock/2, (100-clock+1)/2))
+}
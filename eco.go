@@ -0,0 +1,88 @@
+package main
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+//go:embed eco.txt
+var ecoTable string
+
+// ecoEntry is an opening of the ECO classification
+type ecoEntry struct {
+	code, name string
+}
+
+// ecoPositions maps the position reached by each opening line to the
+// opening, so that transpositions are recognised. It is built on first use.
+var (
+	ecoOnce      sync.Once
+	ecoPositions map[string]ecoEntry
+)
+
+// ecoKey identifies a position for opening lookup: placement, side to move
+// and castling rights. The en passant field is left out so that a line
+// ending in a double pawn push matches however it was reached.
+func ecoKey(b *Board) string {
+	return strings.Join(strings.Fields(b.ToFEN())[:3], " ")
+}
+
+func loadECO() {
+	ecoPositions = make(map[string]ecoEntry)
+	for _, line := range strings.Split(ecoTable, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) != 3 {
+			continue
+		}
+		board := NewBoard()
+		ok := true
+		for _, notation := range strings.Fields(fields[2]) {
+			oldPos, newPos, _, err := ParseMove(notation)
+			if err == nil {
+				err = board.Move(oldPos, newPos, board.ToMove())
+			}
+			if err != nil {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			ecoPositions[ecoKey(board)] = ecoEntry{code: fields[0], name: fields[1]}
+		}
+	}
+}
+
+// ECO returns the ECO code and name of the opening played, from the
+// deepest position of the game found in the embedded table, or empty
+// strings if none matches or the game didn't start from the initial position
+func (g *Game) ECO() (code, name string) {
+	if len(g.openings) == 0 {
+		return "", ""
+	}
+	opening := g.openings[len(g.openings)-1]
+	return opening.code, opening.name
+}
+
+// openingAfterMove returns the opening once the last move of g has been
+// played: the table's entry for the position on the board, or else the
+// opening before the move. Play records it, so that ECO doesn't have to
+// replay the game on every render.
+func (g *Game) openingAfterMove() ecoEntry {
+	var opening ecoEntry
+	if n := len(g.openings); n > 0 {
+		opening = g.openings[n-1]
+	}
+	if g.Start != NewBoard().ToFEN() {
+		return opening
+	}
+	ecoOnce.Do(loadECO)
+	if entry, ok := ecoPositions[ecoKey(g.Board)]; ok {
+		opening = entry
+	}
+	return opening
+}
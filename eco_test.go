@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestECO(t *testing.T) {
+	g := NewGame()
+	moves := []string{"e2-e4", "e7-e5", "g1-f3", "b8-c6", "f1-b5", "g8-f6", "e1-g1", "h7-h6"}
+	want := []string{"", "B00", "C20", "C40", "C44", "C60", "C65", "C65", "C65"}
+	codes := []string{g.ecoCode()}
+	for _, notation := range moves {
+		m, err := ParseCoordinateMove(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Play(m); err != nil {
+			t.Fatalf("%s: %v", notation, err)
+		}
+		codes = append(codes, g.ecoCode())
+	}
+	for i, code := range codes {
+		if code != want[i] {
+			t.Errorf("after %d moves: ECO %q, want %q", i, code, want[i])
+		}
+	}
+	// Undo goes back to the opening before the move
+	for i := len(moves) - 1; i >= 0; i-- {
+		g.Undo()
+		if code := g.ecoCode(); code != want[i] {
+			t.Errorf("undo to %d moves: ECO %q, want %q", i, code, want[i])
+		}
+	}
+
+	// Games from other positions have no opening
+	g, err := NewGameFromFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBN1 w Qkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ParseCoordinateMove("e2-e4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Play(m); err != nil {
+		t.Fatal(err)
+	}
+	if code := g.ecoCode(); code != "" {
+		t.Errorf("game without the h1 rook: ECO %q", code)
+	}
+}
+
+// ecoCode returns the ECO code of g, or "" without one
+func (g *Game) ecoCode() string {
+	code, _ := g.ECO()
+	return code
+}
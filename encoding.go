@@ -0,0 +1,67 @@
+package main
+
+import "fmt"
+
+// Compact move encoding
+//
+// A move fits in 16 bits for network play: the from-square in bits 0-5, the
+// to-square in bits 6-11 (row*8 + col, row 0 being rank 8) and a flag in
+// bits 12-15. Everything else, such as the captured piece, is resolved from
+// the board when decoding.
+
+// Move encoding flags
+const (
+	encQuiet     = 0
+	encCastling  = 1
+	encEnPassant = 2
+	// Promotions are encPromotion plus the index in promotionChoices
+	encPromotion = 4
+)
+
+// Encode packs the move into 16 bits
+func (m Move) Encode() uint16 {
+	flag := encQuiet
+	switch {
+	case m.IsCastling:
+		flag = encCastling
+	case m.IsEnPassant:
+		flag = encEnPassant
+	case m.Promotion != Pawn:
+		for i, pt := range promotionChoices {
+			if pt == m.Promotion {
+				flag = encPromotion + i
+			}
+		}
+	}
+	from := m.From.Row*8 + m.From.Col
+	to := m.To.Row*8 + m.To.Col
+	return uint16(from | to<<6 | flag<<12)
+}
+
+// DecodeMove unpacks a move encoded with Encode and validates it for the
+// side to move on b
+func DecodeMove(u uint16, b *Board) (Move, error) {
+	from := int(u & 0x3f)
+	to := int(u >> 6 & 0x3f)
+	flag := int(u >> 12)
+
+	m := Move{From: Position{from / 8, from % 8}, To: Position{to / 8, to % 8}}
+	switch {
+	case flag == encQuiet, flag == encCastling, flag == encEnPassant:
+	case flag >= encPromotion && flag-encPromotion < len(promotionChoices):
+		m.Promotion = promotionChoices[flag-encPromotion]
+	default:
+		// Flags that Encode never writes, such as 3, mean corrupt input
+		return Move{}, fmt.Errorf("invalid move encoding %#04x", u)
+	}
+
+	move, err := b.ResolveMove(m, b.ToMove())
+	if err != nil {
+		return Move{}, err
+	}
+	if move.IsCastling != (flag == encCastling) || move.IsEnPassant != (flag == encEnPassant) ||
+		(flag < encPromotion && move.Promotion != Pawn) {
+		return Move{}, fmt.Errorf("move encoding %#04x doesn't match the move %s", u, move)
+	}
+	return move, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestEncodeRoundTrip(t *testing.T) {
+	walkPositions(t, func(b *Board) bool {
+		for _, move := range b.LegalMoves(b.ToMove()) {
+			decoded, err := DecodeMove(move.Encode(), b)
+			if err != nil {
+				t.Errorf("%s: %s: %v", b.ToFEN(), move, err)
+				return false
+			}
+			if !sameMove(decoded, move) || decoded.IsCastling != move.IsCastling || decoded.IsEnPassant != move.IsEnPassant {
+				t.Errorf("%s: %s decoded as %s", b.ToFEN(), move, decoded)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestDecodeMoveFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		move  string
+		valid []int // The flags the move decodes with
+	}{
+		{"quiet", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2-e4", []int{encQuiet}},
+		{"castling", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1-g1", []int{encCastling}},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5-d6", []int{encEnPassant}},
+		{"promotion", "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7-a8", []int{encPromotion, encPromotion + 1, encPromotion + 2, encPromotion + 3}},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		parsed, err := ParseCoordinateMove(tt.move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		squares := uint16(squareIndex(parsed.From) | squareIndex(parsed.To)<<6)
+		for flag := 0; flag < 16; flag++ {
+			valid := false
+			for _, f := range tt.valid {
+				valid = valid || f == flag
+			}
+			move, err := DecodeMove(squares|uint16(flag)<<12, board)
+			switch {
+			case valid && err != nil:
+				t.Errorf("%s: flag %d: %v", tt.name, flag, err)
+			case !valid && err == nil:
+				t.Errorf("%s: flag %d decoded as %s, want an error", tt.name, flag, move)
+			}
+		}
+		if got := board.ToFEN(); got != tt.fen {
+			t.Errorf("%s: decoding changed the board to %s", tt.name, got)
+		}
+	}
+}
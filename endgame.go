@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// endgames are the practice positions selectable with -endgame, named by
+// the material of each side. White has the winning side and moves first.
+var endgames = map[string]string{
+	"KQvK": "8/8/8/4k3/8/8/8/3QK3 w - - 0 1",
+	"KRvK": "8/8/8/4k3/8/8/8/R3K3 w - - 0 1",
+	"KPvK": "4k3/8/4K3/4P3/8/8/8/8 w - - 0 1",
+}
+
+// endgameNames returns the practice endgame names in order
+func endgameNames() []string {
+	names := make([]string, 0, len(endgames))
+	for name := range endgames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EndgamePosition returns the FEN of the named practice endgame. With rng,
+// the same material is placed on random squares instead, in a position
+// where White is to move and the game isn't already over.
+func EndgamePosition(name string, rng *rand.Rand) (string, error) {
+	fen, ok := endgames[name]
+	if !ok {
+		return "", fmt.Errorf("unknown endgame %q (available: %s)", name, strings.Join(endgameNames(), ", "))
+	}
+	if rng == nil {
+		return fen, nil
+	}
+
+	// The name lists each side's pieces, e.g. "KRvK"
+	white, black, _ := strings.Cut(name, "v")
+	letters := white + strings.ToLower(black)
+	for {
+		pieces := make(map[string]rune)
+		for _, letter := range letters {
+			square := randomSquare(rng, letter == 'P' || letter == 'p')
+			for pieces[square] != 0 {
+				square = randomSquare(rng, letter == 'P' || letter == 'p')
+			}
+			pieces[square] = letter
+		}
+
+		b, err := BoardFromMap(pieces, White)
+		if err != nil {
+			continue
+		}
+		// Round trip through FEN to validate, e.g. that Black isn't in check
+		b, err = BoardFromFEN(b.ToFEN())
+		if err != nil || b.Result().IsOver() {
+			continue
+		}
+		return b.ToFEN(), nil
+	}
+}
+
+// randomSquare returns a random square, kept off the first and last ranks
+// for pawns
+func randomSquare(rng *rand.Rand, pawn bool) string {
+	if pawn {
+		return Position{1 + rng.Intn(6), rng.Intn(8)}.String()
+	}
+	return Position{rng.Intn(8), rng.Intn(8)}.String()
+}
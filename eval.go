@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultPieceValues are the standard material values in centipawns
+var DefaultPieceValues = map[PieceType]int{
+	Pawn:   100,
+	Knight: 320,
+	Bishop: 330,
+	Rook:   500,
+	Queen:  900,
+	King:   0,
+}
+
+// pieceValues holds the values used by the engine heuristics (evaluation and
+// capture ordering). They never affect which moves are legal.
+var pieceValues = copyPieceValues(DefaultPieceValues)
+
+func copyPieceValues(values map[PieceType]int) map[PieceType]int {
+	c := make(map[PieceType]int, len(values))
+	for pt, v := range values {
+		c[pt] = v
+	}
+	return c
+}
+
+// SetPieceValues overrides the value of the given piece types for variant
+// play (e.g. a weak queen). Types not present keep their current value.
+func SetPieceValues(overrides map[PieceType]int) {
+	for pt, v := range overrides {
+		pieceValues[pt] = v
+	}
+}
+
+// ResetPieceValues restores the default piece values
+func ResetPieceValues() {
+	pieceValues = copyPieceValues(DefaultPieceValues)
+}
+
+// ParsePieceValues parses overrides of the form "q=500,n=300"
+func ParsePieceValues(spec string) (map[PieceType]int, error) {
+	overrides := make(map[PieceType]int)
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		letter, value, ok := strings.Cut(field, "=")
+		if !ok || len(letter) != 1 {
+			return nil, fmt.Errorf("invalid piece value %q (example: q=500)", field)
+		}
+		pt, ok := pieceTypeFromLetter(strings.ToLower(letter)[0])
+		if !ok {
+			return nil, fmt.Errorf("unknown piece letter %q", letter)
+		}
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %v", letter, err)
+		}
+		overrides[pt] = v
+	}
+	return overrides, nil
+}
+
+// pieceTypeFromLetter maps a lowercase FEN/SAN letter to its piece type
+func pieceTypeFromLetter(c byte) (PieceType, bool) {
+	for pt, letter := range pieceLetters {
+		if letter == c {
+			return pt, true
+		}
+	}
+	return 0, false
+}
+
+// EvalWeights are the positional bonuses of the evaluation in centipawns
+type EvalWeights struct {
+	RookOpenFile     int // Rook on a file without pawns
+	RookHalfOpenFile int // Rook on a file with only opponent pawns
+	RookSeventh      int // Rook on the opponent's second rank
+	KingAttack       int // Each piece within two squares of the enemy king
+	KingSafety       int // Penalty for an exposed king
+	LoosePiece       int // Penalty for each piece the opponent can win
+	PassedPawn       int // Each passed pawn
+	BishopPair       int // Bishops on both square colours
+	KnightBishop     int // Knights gain and bishops lose this much per 8 pawns on the board beyond 8
+	RookMinors       int // Two minor pieces traded for a rook
+}
+
+// DefaultEvalWeights are the positional bonuses used unless overridden
+var DefaultEvalWeights = EvalWeights{
+	RookOpenFile:     25,
+	RookHalfOpenFile: 10,
+	RookSeventh:      20,
+	BishopPair:       30,
+	KnightBishop:     16,
+	RookMinors:       25,
+}
+
+// evalStyles are the engine personalities selectable with -style, as
+// weights on top of the material balance
+var evalStyles = map[string]EvalWeights{
+	"balanced": DefaultEvalWeights,
+	// Throws pieces at the enemy king, even at the cost of a pawn or two
+	"aggressive": {RookOpenFile: 25, RookHalfOpenFile: 15, RookSeventh: 30, KingAttack: 40,
+		BishopPair: 30, KnightBishop: 16, RookMinors: 25},
+	// Keeps its king covered and its pieces protected
+	"defensive": {RookOpenFile: 15, RookHalfOpenFile: 5, RookSeventh: 10, KingSafety: 60, LoosePiece: 50,
+		BishopPair: 30, KnightBishop: 16, RookMinors: 25},
+	// Plays for open files and passed pawns
+	"positional": {RookOpenFile: 35, RookHalfOpenFile: 20, RookSeventh: 25, PassedPawn: 40,
+		BishopPair: 30, KnightBishop: 16, RookMinors: 25},
+}
+
+// evalWeights holds the bonuses used by Evaluate
+var evalWeights = DefaultEvalWeights
+
+// evalWeightNames maps the names accepted by ParseEvalWeights to the fields
+var evalWeightNames = map[string]func(w *EvalWeights) *int{
+	"open":         func(w *EvalWeights) *int { return &w.RookOpenFile },
+	"halfopen":     func(w *EvalWeights) *int { return &w.RookHalfOpenFile },
+	"seventh":      func(w *EvalWeights) *int { return &w.RookSeventh },
+	"kingattack":   func(w *EvalWeights) *int { return &w.KingAttack },
+	"kingsafety":   func(w *EvalWeights) *int { return &w.KingSafety },
+	"loose":        func(w *EvalWeights) *int { return &w.LoosePiece },
+	"passed":       func(w *EvalWeights) *int { return &w.PassedPawn },
+	"bishoppair":   func(w *EvalWeights) *int { return &w.BishopPair },
+	"knightbishop": func(w *EvalWeights) *int { return &w.KnightBishop },
+	"rookminors":   func(w *EvalWeights) *int { return &w.RookMinors },
+}
+
+// ParseEvalWeights parses overrides of the form "open=30,seventh=15" on top
+// of the base weights
+func ParseEvalWeights(spec string, base EvalWeights) (EvalWeights, error) {
+	weights := base
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return weights, fmt.Errorf("invalid eval weight %q (example: open=30)", field)
+		}
+		weight, ok := evalWeightNames[name]
+		if !ok {
+			return weights, fmt.Errorf("unknown eval weight %q", name)
+		}
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return weights, fmt.Errorf("invalid value for %s: %v", name, err)
+		}
+		*weight(&weights) = v
+	}
+	return weights, nil
+}
+
+// Evaluate returns the static evaluation in centipawns from player's point
+// of view: the material balance plus positional bonuses
+func (b *Board) Evaluate(player Player) int {
+	score := b.Material(player)
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece == nil || piece.Type != Rook {
+				continue
+			}
+			bonus := b.rookBonus(piece.Player, Position{row, col})
+			if piece.Player == player {
+				score += bonus
+			} else {
+				score -= bonus
+			}
+		}
+	}
+	return score + b.styleBonus(player) - b.styleBonus(1-player) + b.imbalance(player)
+}
+
+// styleBonus scores the terms that give the engine styles their character.
+// They are skipped when their weight is zero, as some are costly.
+func (b *Board) styleBonus(player Player) int {
+	bonus := 0
+	if evalWeights.KingAttack != 0 {
+		bonus += evalWeights.KingAttack * b.kingAttackers(player)
+	}
+	// In the endgame the king belongs in the game, not behind its pawns
+	if evalWeights.KingSafety != 0 && b.IsKingExposed(player) && b.Phase() != Endgame {
+		bonus -= evalWeights.KingSafety
+	}
+	if evalWeights.LoosePiece != 0 {
+		bonus -= evalWeights.LoosePiece * b.loosePieces(player)
+	}
+	if evalWeights.PassedPawn != 0 {
+		bonus += evalWeights.PassedPawn * len(b.PassedPawns(player))
+	}
+	return bonus
+}
+
+// kingAttackers counts player's pieces other than pawns and the king within
+// two squares of the opponent's king
+func (b *Board) kingAttackers(player Player) int {
+	king := b.blackKing
+	if player == Black {
+		king = b.whiteKing
+	}
+	n := 0
+	for row := max(king.Row-2, 0); row <= min(king.Row+2, 7); row++ {
+		for col := max(king.Col-2, 0); col <= min(king.Col+2, 7); col++ {
+			piece := b.squares[row][col]
+			if piece != nil && piece.Player == player && piece.Type != Pawn && piece.Type != King {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// loosePieces counts player's pieces the opponent could win material
+// capturing
+func (b *Board) loosePieces(player Player) int {
+	n := 0
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece != nil && piece.Player == player && piece.Type != King && b.SEE(Position{row, col}, 1-player) > 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Material returns the material balance in centipawns from player's point
+// of view
+func (b *Board) Material(player Player) int {
+	score := 0
+	for pt := Pawn; pt <= King; pt++ {
+		score += pieceValues[pt] * (b.PieceCount(player, pt) - b.PieceCount(1-player, pt))
+	}
+	return score
+}
+
+// rookBonus rewards a rook of player on pos for an open or half-open file
+// and for reaching the seventh rank
+func (b *Board) rookBonus(player Player, pos Position) int {
+	bonus := 0
+	if b.isOpenFile(pos.Col) {
+		bonus += evalWeights.RookOpenFile
+	} else if b.isHalfOpenFile(player, pos.Col) {
+		bonus += evalWeights.RookHalfOpenFile
+	}
+	seventh := 1
+	if player == Black {
+		seventh = 6
+	}
+	if pos.Row == seventh {
+		bonus += evalWeights.RookSeventh
+	}
+	return bonus
+}
+
+// captureScore ranks a capture for MVV-LVA ordering: the most valuable
+// victim first, then the least valuable attacker
+func captureScore(move Move) int {
+	victim := Pawn // En passant captures a pawn from an empty square
+	if move.Captured != nil {
+		victim = move.Captured.Type
+	}
+	return 10*pieceValues[victim] - pieceValues[move.Piece.Type]
+}
+
+// isCapture reports whether the move removes an opponent's piece
+func isCapture(move Move) bool {
+	return move.Captured != nil || move.IsEnPassant
+}
+
+// OrderMoves sorts moves so that captures come first, ordered by MVV-LVA
+func OrderMoves(moves []Move) {
+	sort.SliceStable(moves, func(i, j int) bool {
+		ci, cj := isCapture(moves[i]), isCapture(moves[j])
+		if ci != cj {
+			return ci
+		}
+		return ci && captureScore(moves[i]) > captureScore(moves[j])
+	})
+}
+
+// MateIn returns the smallest number of moves n <= maxMoves in which the
+// side to move can force checkmate
+func (b *Board) MateIn(maxMoves int) (int, bool) {
+	for n := 1; n <= maxMoves; n++ {
+		if b.canForceMate(b.ToMove(), n) {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// canForceMate reports whether player, to move, can checkmate within n moves
+// whatever the opponent replies
+func (b *Board) canForceMate(player Player, n int) bool {
+	opponent := 1 - player
+	for _, move := range b.LegalMoves(player) {
+		b.makeMove(move)
+		forced := b.IsCheckmate(opponent)
+		if !forced && n > 1 {
+			replies := b.LegalMoves(opponent)
+			// No replies without check is stalemate, not mate
+			forced = len(replies) > 0
+			for _, reply := range replies {
+				b.makeMove(reply)
+				ok := b.canForceMate(player, n-1)
+				b.undoMove(reply)
+				if !ok {
+					forced = false
+					break
+				}
+			}
+		}
+		b.undoMove(move)
+		if forced {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package main
+
+import "testing"
+
+func TestOrderMovesPieceValues(t *testing.T) {
+	// The knight on d4 can take the queen on c6 or the rook on e6; a weak
+	// queen must send the rook capture to the front
+	tests := []struct{ spec, first string }{
+		{"", "Nxc6"},
+		{"q=400", "Nxe6"},
+		{"r=1000", "Nxe6"},
+		{"q=400,r=300", "Nxc6"},
+	}
+	t.Cleanup(ResetPieceValues)
+	for _, tt := range tests {
+		overrides, err := ParsePieceValues(tt.spec)
+		if err != nil {
+			t.Fatalf("ParsePieceValues(%q): %v", tt.spec, err)
+		}
+		ResetPieceValues()
+		SetPieceValues(overrides)
+		board, err := BoardFromFEN("4k3/8/2q1r3/8/3N4/8/8/6K1 w - - 0 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		moves := board.LegalMoves(White)
+		OrderMoves(moves)
+		if got := board.SAN(moves[0]); got != tt.first {
+			t.Errorf("values %q: first move %s, want %s", tt.spec, got, tt.first)
+		}
+	}
+}
+
+func TestBishopPair(t *testing.T) {
+	// Only the bishop pair term counts, so the knight-against-bishop
+	// weighting doesn't blur the scores
+	t.Cleanup(func() { evalWeights = DefaultEvalWeights })
+	evalWeights = EvalWeights{BishopPair: 30}
+	tests := []struct {
+		name string
+		fen  string
+		want int // imbalance for White
+	}{
+		{"pair against bishop and knight", "rn2k3/pppppppp/8/8/8/8/PPPPPPPP/2B1KB2 w - - 0 1", 30},
+		{"same-coloured bishops", "rn2k3/pppppppp/8/8/8/4B3/PPPPPPPP/2B1K3 w - - 0 1", 0},
+		{"pair for Black", "2b1kb2/pppppppp/8/8/8/8/PPPPPPPP/1N1BK3 w - - 0 1", -30},
+		{"a pair each", "2b1kb2/pppppppp/8/8/8/8/PPPPPPPP/2B1KB2 w - - 0 1", 0},
+		{"pair against same-coloured bishops", "2b1k3/pppppppp/4b3/8/8/8/PPPPPPPP/2B1KB2 w - - 0 1", 30},
+		{"single bishop", "4k3/pppppppp/8/8/8/8/PPPPPPPP/2B1K3 w - - 0 1", 0},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := board.imbalance(White); got != tt.want {
+			t.Errorf("%s: imbalance(White) = %d, want %d", tt.name, got, tt.want)
+		}
+		if got := board.imbalance(Black); got != -tt.want {
+			t.Errorf("%s: imbalance(Black) = %d, want %d", tt.name, got, -tt.want)
+		}
+	}
+
+	// With the same material on both sides, the pair alone tips the
+	// evaluation
+	board, err := BoardFromFEN("2b1k3/pppppppp/4b3/8/8/8/PPPPPPPP/2B1KB2 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	with := board.Evaluate(White)
+	evalWeights = EvalWeights{}
+	if without := board.Evaluate(White); with-without != 30 {
+		t.Errorf("the bishop pair adds %d to the evaluation, want 30", with-without)
+	}
+}
+
+func TestRookBonus(t *testing.T) {
+	t.Cleanup(func() { evalWeights = DefaultEvalWeights })
+	evalWeights = EvalWeights{RookOpenFile: 25, RookHalfOpenFile: 10, RookSeventh: 30}
+	tests := []struct {
+		name   string
+		fen    string
+		player Player
+		rook   string
+		want   int
+	}{
+		{"open file", "4k3/pp3ppp/8/8/8/8/PP3PPP/3RK3 w - - 0 1", White, "d1", 25},
+		{"half-open file", "4k3/pp1p1ppp/8/8/8/8/PP3PPP/3RK3 w - - 0 1", White, "d1", 10},
+		{"closed file", "4k3/pp1p1ppp/8/8/8/8/PP1P1PPP/3RK3 w - - 0 1", White, "d1", 0},
+		{"only own pawn", "4k3/pp3ppp/8/8/8/8/PP1P1PPP/3RK3 w - - 0 1", White, "d1", 0},
+		{"half-open for Black", "3rk3/pp3ppp/8/8/3P4/8/PP3PPP/4K3 w - - 0 1", Black, "d8", 10},
+		{"open file on the seventh", "4k3/pp1R1ppp/8/8/8/8/PP3PPP/4K3 w - - 0 1", White, "d7", 55},
+		{"own pawn on the file, on the seventh", "4k3/8/8/8/3p4/8/3r4/4K3 w - - 0 1", Black, "d2", 30},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		rook, err := ParseSquare(tt.rook)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := board.rookBonus(tt.player, rook); got != tt.want {
+			t.Errorf("%s: rookBonus = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
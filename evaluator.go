@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Evaluator scores a position in centipawns from player's point of view.
+// The search calls it at the leaves of the tree, so it decides what the
+// engine plays for; it must not leave the board changed.
+type Evaluator interface {
+	Evaluate(b *Board, player Player) int
+}
+
+// EvaluatorFunc adapts an ordinary function to the Evaluator interface
+type EvaluatorFunc func(b *Board, player Player) int
+
+func (f EvaluatorFunc) Evaluate(b *Board, player Player) int {
+	return f(b, player)
+}
+
+// DefaultEvaluator is the built-in evaluation: material plus the
+// positional terms weighted by -style and -eval-weights
+type DefaultEvaluator struct{}
+
+func (DefaultEvaluator) Evaluate(b *Board, player Player) int {
+	return b.Evaluate(player)
+}
+
+// evaluators are the evaluators selectable with -evaluator
+var evaluators = map[string]Evaluator{
+	"default": DefaultEvaluator{},
+	"material": EvaluatorFunc(func(b *Board, player Player) int {
+		return b.Material(player)
+	}),
+}
+
+// evaluator is the evaluator used by the engine, set from the command line
+var evaluator Evaluator = DefaultEvaluator{}
+
+// RegisterEvaluator makes eval selectable with -evaluator under name. Call
+// it before flags are parsed, e.g. from an init function in a new file.
+func RegisterEvaluator(name string, eval Evaluator) error {
+	if _, ok := evaluators[name]; ok {
+		return fmt.Errorf("evaluator %q is already registered", name)
+	}
+	evaluators[name] = eval
+	return nil
+}
+
+// evaluatorNames returns the registered evaluator names in order
+func evaluatorNames() []string {
+	names := make([]string, 0, len(evaluators))
+	for name := range evaluators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
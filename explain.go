@@ -0,0 +1,167 @@
+package main
+
+import "fmt"
+
+// pieceMoveRules describes how each piece moves, for explanations
+var pieceMoveRules = map[PieceType]string{
+	Pawn:   "a pawn moves straight ahead one square, or two from its starting square, and captures diagonally",
+	Rook:   "a rook moves along ranks and files",
+	Knight: "a knight moves in an L shape: two squares one way and one square to the side",
+	Bishop: "a bishop moves along diagonals",
+	Queen:  "a queen moves along ranks, files and diagonals",
+	King:   "a king moves one square in any direction, or two towards a rook when castling",
+}
+
+// ExplainIllegal describes in plain words why player can't move from from
+// to to, pointing at the pieces involved, or returns "" if the move is
+// legal. The board is left unchanged.
+func (b *Board) ExplainIllegal(from, to Position, player Player) string {
+	if !isValidPosition(from) || !isValidPosition(to) {
+		return "Both squares must be on the board, from a1 to h8."
+	}
+	piece := b.squares[from.Row][from.Col]
+	if piece == nil {
+		return fmt.Sprintf("There is no piece on %s.", from)
+	}
+	if piece.Player != player {
+		return fmt.Sprintf("The %s on %s belongs to %s; it is %s's turn.", piece.Type, from, piece.Player, player)
+	}
+	if from == to {
+		return fmt.Sprintf("The %s must move to a different square.", piece.Type)
+	}
+	if target := b.squares[to.Row][to.Col]; target != nil && target.Player == player {
+		return fmt.Sprintf("Your own %s is on %s; you can't capture your own piece.", target.Type, to)
+	}
+
+	move, err := b.ValidateMove(from, to, player)
+	if err != nil {
+		return b.explainPieceMove(piece, from, to)
+	}
+
+	b.makeMove(move)
+	checkers := b.checkers(player)
+	b.undoMove(move)
+	if len(checkers) > 0 {
+		c := checkers[0]
+		attacker := b.squares[c.Row][c.Col]
+		if piece.Type == King {
+			return fmt.Sprintf("Your king would be in check on %s from the %s on %s.", to, attacker.Type, c)
+		}
+		return fmt.Sprintf("This leaves your king in check from the %s on %s.", attacker.Type, c)
+	}
+	return ""
+}
+
+// explainPieceMove explains why the piece can't reach to, given that the
+// destination itself is free or holds an opponent's piece
+func (b *Board) explainPieceMove(piece *Piece, from, to Position) string {
+	dr, dc := to.Row-from.Row, to.Col-from.Col
+	target := b.squares[to.Row][to.Col]
+
+	switch piece.Type {
+	case Pawn:
+		forward := -1
+		if piece.Player == Black {
+			forward = 1
+		}
+		switch {
+		case dc == 0 && (dr == forward || dr == 2*forward):
+			if dr == 2*forward && piece.HasMoved {
+				return "A pawn can only advance two squares from its starting square."
+			}
+			if blocker, ok := b.firstBlocker(from, to, true); ok {
+				return b.blockedBy(piece, to, blocker)
+			}
+		case abs(dc) == 1 && dr == forward && target == nil:
+			return "A pawn only moves diagonally when capturing, or en passant right after a two-square advance."
+		case dr*forward < 0:
+			return "A pawn can't move backwards."
+		}
+	case King:
+		if dr == 0 && abs(dc) == 2 {
+			return b.explainCastling(piece, from, to)
+		}
+	case Rook, Bishop, Queen:
+		straight := dr == 0 || dc == 0
+		diagonal := abs(dr) == abs(dc)
+		if (piece.Type == Rook && straight) || (piece.Type == Bishop && diagonal) || (piece.Type == Queen && (straight || diagonal)) {
+			if blocker, ok := b.firstBlocker(from, to, false); ok {
+				return b.blockedBy(piece, to, blocker)
+			}
+		}
+	}
+	rule := pieceMoveRules[piece.Type]
+	return fmt.Sprintf("The %s on %s can't reach %s: %s.", piece.Type, from, to, rule)
+}
+
+// explainCastling explains why the king can't castle towards to
+func (b *Board) explainCastling(king *Piece, from, to Position) string {
+	kingSide := to.Col > from.Col
+	side := "kingside"
+	rookCol := 7
+	if !kingSide {
+		side = "queenside"
+		rookCol = 0
+	}
+
+	if !b.CanCastle(king.Player, kingSide) {
+		return fmt.Sprintf("You can no longer castle %s: the king or that rook has already moved.", side)
+	}
+	if blocker, ok := b.firstBlocker(from, Position{from.Row, rookCol}, false); ok {
+		p := b.squares[blocker.Row][blocker.Col]
+		return fmt.Sprintf("You can't castle %s while the %s on %s is in the way.", side, p.Type, blocker)
+	}
+	if b.IsInCheck(king.Player) {
+		return "You can't castle out of check."
+	}
+	return fmt.Sprintf("You can't castle %s through or into check.", side)
+}
+
+// firstBlocker returns the first occupied square strictly between from and
+// to, or including to when includeTo is set (pawns can't capture forward)
+func (b *Board) firstBlocker(from, to Position, includeTo bool) (Position, bool) {
+	dr, dc := sign(to.Row-from.Row), sign(to.Col-from.Col)
+	for pos := (Position{from.Row + dr, from.Col + dc}); ; pos = (Position{pos.Row + dr, pos.Col + dc}) {
+		if pos == to && !includeTo {
+			return Position{}, false
+		}
+		if b.squares[pos.Row][pos.Col] != nil {
+			return pos, true
+		}
+		if pos == to {
+			return Position{}, false
+		}
+	}
+}
+
+// blockedBy explains that the piece's path to to is blocked on blocker
+func (b *Board) blockedBy(piece *Piece, to, blocker Position) string {
+	p := b.squares[blocker.Row][blocker.Col]
+	owner := "your"
+	if p.Player != piece.Player {
+		owner = fmt.Sprintf("%s's", p.Player)
+	}
+	return fmt.Sprintf("The %s can't move to %s because the path is blocked by %s %s on %s.", piece.Type, to, owner, p.Type, blocker)
+}
+
+// checkers returns the squares of the opponent's pieces giving check to
+// player's king
+func (b *Board) checkers(player Player) []Position {
+	king := b.whiteKing
+	if player == Black {
+		king = b.blackKing
+	}
+	var checkers []Position
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece == nil || piece.Player == player {
+				continue
+			}
+			if _, err := b.ValidateMove(Position{row, col}, king, piece.Player); err == nil {
+				checkers = append(checkers, Position{row, col})
+			}
+		}
+	}
+	return checkers
+}
@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestExplainIllegal(t *testing.T) {
+	start := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	tests := []struct {
+		name, fen, move, want string
+	}{
+		{"no piece", start, "e4-e5", "There is no piece on e4."},
+		{"opponent's piece", start, "e7-e5", "The pawn on e7 belongs to Black; it is White's turn."},
+		{"same square", start, "e2-e2", "The pawn must move to a different square."},
+		{"own piece", start, "d1-d2", "Your own pawn is on d2; you can't capture your own piece."},
+		{"pawn two squares after moving", "4k3/8/8/8/8/4P3/8/4K3 w - - 0 1", "e3-e5",
+			"A pawn can only advance two squares from its starting square."},
+		{"pawn blocked", "4k3/8/8/8/8/4n3/4P3/4K3 w - - 0 1", "e2-e4",
+			"The pawn can't move to e4 because the path is blocked by Black's knight on e3."},
+		{"pawn diagonal", start, "e2-d3",
+			"A pawn only moves diagonally when capturing, or en passant right after a two-square advance."},
+		{"pawn backwards", "4k3/8/8/8/4P3/8/8/4K3 w - - 0 1", "e4-e3", "A pawn can't move backwards."},
+		{"castling rights lost", "4k3/8/8/8/8/8/8/4K2R w - - 0 1", "e1-g1",
+			"You can no longer castle kingside: the king or that rook has already moved."},
+		{"castling blocked", "4k3/8/8/8/8/8/8/4KB1R w K - 0 1", "e1-g1",
+			"You can't castle kingside while the bishop on f1 is in the way."},
+		{"castling out of check", "4k3/4r3/8/8/8/8/8/4K2R w K - 0 1", "e1-g1", "You can't castle out of check."},
+		{"castling through check", "4k3/5r2/8/8/8/8/8/4K2R w K - 0 1", "e1-g1",
+			"You can't castle kingside through or into check."},
+		{"slider blocked", start, "a1-a3",
+			"The rook can't move to a3 because the path is blocked by your pawn on a2."},
+		{"wrong shape", start, "g1-g3",
+			"The knight on g1 can't reach g3: a knight moves in an L shape: two squares one way and one square to the side."},
+		{"king into check", "4k3/3r4/8/8/8/8/8/4K3 w - - 0 1", "e1-d1",
+			"Your king would be in check on d1 from the rook on d7."},
+		{"pinned piece", "4k3/4r3/8/8/8/8/4B3/4K3 w - - 0 1", "e2-d3",
+			"This leaves your king in check from the rook on e7."},
+		{"legal", start, "e2-e4", ""},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		parsed, err := ParseCoordinateMove(tt.move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := board.ExplainIllegal(parsed.From, parsed.To, White); got != tt.want {
+			t.Errorf("%s: ExplainIllegal(%s) = %q, want %q", tt.name, tt.move, got, tt.want)
+		}
+	}
+
+	board := NewBoard()
+	if got, want := board.ExplainIllegal(Position{6, 4}, Position{8, 4}, White), "Both squares must be on the board, from a1 to h8."; got != want {
+		t.Errorf("off the board: %q, want %q", got, want)
+	}
+}
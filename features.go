@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HasBishopPair reports whether player has bishops on both square colours
+func (b *Board) HasBishopPair(player Player) bool {
+	var light, dark bool
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece == nil || piece.Type != Bishop || piece.Player != player {
+				continue
+			}
+			if (row+col)%2 == 0 {
+				light = true
+			} else {
+				dark = true
+			}
+		}
+	}
+	return light && dark
+}
+
+// RooksOnSeventh returns the squares of player's rooks on the opponent's
+// second rank
+func (b *Board) RooksOnSeventh(player Player) []Position {
+	row := 1
+	if player == Black {
+		row = 6
+	}
+	var rooks []Position
+	for col := 0; col < 8; col++ {
+		piece := b.squares[row][col]
+		if piece != nil && piece.Type == Rook && piece.Player == player {
+			rooks = append(rooks, Position{row, col})
+		}
+	}
+	return rooks
+}
+
+// IsKingExposed reports whether player's king has fewer than two of its own
+// pawns shielding it on the three files around it, within two ranks ahead
+func (b *Board) IsKingExposed(player Player) bool {
+	king := b.whiteKing
+	forward := -1
+	if player == Black {
+		king = b.blackKing
+		forward = 1
+	}
+
+	shield := 0
+	for _, dr := range []int{forward, 2 * forward} {
+		row := king.Row + dr
+		if row < 0 || row > 7 {
+			continue
+		}
+		for col := max(king.Col-1, 0); col <= min(king.Col+1, 7); col++ {
+			piece := b.squares[row][col]
+			if piece != nil && piece.Type == Pawn && piece.Player == player {
+				shield++
+			}
+		}
+	}
+	return shield < 2
+}
+
+// PositionalReport describes the notable positional features of the
+// position for both sides, one line per side. The board is not changed.
+func (b *Board) PositionalReport() []string {
+	var lines []string
+	for _, player := range []Player{White, Black} {
+		var features []string
+		if passed := b.PassedPawns(player); len(passed) > 0 {
+			features = append(features, "passed pawn on "+joinSquares(passed))
+		}
+		if rooks := b.RooksOnSeventh(player); len(rooks) > 0 {
+			features = append(features, "rook on the 7th ("+joinSquares(rooks)+")")
+		}
+		if b.HasBishopPair(player) {
+			features = append(features, "bishop pair")
+		}
+		if b.IsKingExposed(player) {
+			features = append(features, "exposed king")
+		}
+		if len(features) == 0 {
+			features = append(features, "nothing notable")
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", player, strings.Join(features, ", ")))
+	}
+	return append(lines, fmt.Sprintf("Phase: %s", b.Phase()))
+}
+
+func joinSquares(squares []Position) string {
+	names := make([]string, len(squares))
+	for i, sq := range squares {
+		names[i] = sq.String()
+	}
+	return strings.Join(names, ", ")
+}
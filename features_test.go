@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestHasBishopPair(t *testing.T) {
+	tests := []struct {
+		name         string
+		fen          string
+		white, black bool
+	}{
+		{"opposite-coloured bishops", "4k3/8/8/8/8/8/8/2B1KB2 w - - 0 1", true, false},
+		{"same-coloured bishops", "4k3/8/8/8/8/4B3/8/2B1K3 w - - 0 1", false, false},
+		{"single bishop", "2b1k3/8/8/8/8/8/8/4KB2 w - - 0 1", false, false},
+		// A promoted third bishop doesn't matter once both colours are covered
+		{"three bishops", "2b1kb2/8/3b4/8/8/8/8/4K3 w - - 0 1", false, true},
+		{"no bishops", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", false, false},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := board.HasBishopPair(White); got != tt.white {
+			t.Errorf("%s: HasBishopPair(White) = %v, want %v", tt.name, got, tt.white)
+		}
+		if got := board.HasBishopPair(Black); got != tt.black {
+			t.Errorf("%s: HasBishopPair(Black) = %v, want %v", tt.name, got, tt.black)
+		}
+	}
+}
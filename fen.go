@@ -0,0 +1,316 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ToMove returns the side to move, derived from the ply count
+func (b *Board) ToMove() Player {
+	return Player(b.moveCount % 2)
+}
+
+// ToFEN returns the position in Forsyth-Edwards Notation
+func (b *Board) ToFEN() string {
+	var sb strings.Builder
+
+	// Piece placement, from rank 8 down to rank 1
+	for row := 0; row < 8; row++ {
+		empty := 0
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece == nil {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			sb.WriteByte(piece.FENChar())
+		}
+		if empty > 0 {
+			sb.WriteByte(byte('0' + empty))
+		}
+		if row < 7 {
+			sb.WriteByte('/')
+		}
+	}
+
+	// Active color
+	if b.ToMove() == White {
+		sb.WriteString(" w ")
+	} else {
+		sb.WriteString(" b ")
+	}
+
+	sb.WriteString(b.castlingFEN())
+	sb.WriteByte(' ')
+	sb.WriteString(b.enPassantFEN())
+	fmt.Fprintf(&sb, " %d %d", b.halfMoveClock, b.moveCount/2+1)
+
+	return sb.String()
+}
+
+// FENChar returns the FEN letter for the piece, uppercase for White
+func (p *Piece) FENChar() byte {
+	c := pieceLetters[p.Type]
+	if p.Player == White {
+		c -= 'a' - 'A'
+	}
+	return c
+}
+
+// castlingFEN returns the castling availability field, e.g. "KQkq"
+func (b *Board) castlingFEN() string {
+	rights := ""
+	for _, r := range []struct {
+		right  CastlingRights
+		letter string
+	}{{WhiteKingSide, "K"}, {WhiteQueenSide, "Q"}, {BlackKingSide, "k"}, {BlackQueenSide, "q"}} {
+		if b.castling&r.right != 0 {
+			rights += r.letter
+		}
+	}
+	if rights == "" {
+		return "-"
+	}
+	return rights
+}
+
+// enPassantFEN returns the square skipped by a pawn's two-square advance
+// on the previous ply, or "-" if there is none. Like most engines, it also
+// returns "-" when no pawn can legally capture en passant, so positions
+// that only differ by an unusable target get the same FEN.
+func (b *Board) enPassantFEN() string {
+	target, ok := b.enPassantTarget()
+	if !ok || !b.enPassantCapturable() {
+		return "-"
+	}
+	return target.String()
+}
+
+// pieceFromFENChar creates a piece from its FEN letter, uppercase for White
+func pieceFromFENChar(c byte) (*Piece, bool) {
+	player := Black
+	if c >= 'A' && c <= 'Z' {
+		player = White
+		c += 'a' - 'A'
+	}
+	pt, ok := pieceTypeFromLetter(c)
+	if !ok {
+		return nil, false
+	}
+	return NewPiece(pt, player), true
+}
+
+// BoardFromMap builds a position from a map of squares to FEN letters, e.g.
+// {"e1": 'K', "e8": 'k', "d1": 'Q'}. King positions are set automatically
+// and a piece counts as unmoved only if it stands on one of its starting
+// squares. Exactly one king per side is required.
+func BoardFromMap(pieces map[string]rune, toMove Player) (*Board, error) {
+	b := &Board{moveCount: int(toMove)}
+	kings := map[Player]int{}
+
+	for square, letter := range pieces {
+		pos, err := ParseSquare(square)
+		if err != nil {
+			return nil, err
+		}
+		if letter > 0x7f {
+			return nil, fmt.Errorf("invalid piece letter %q on %s", letter, square)
+		}
+		piece, ok := pieceFromFENChar(byte(letter))
+		if !ok {
+			return nil, fmt.Errorf("invalid piece letter %q on %s", letter, square)
+		}
+		piece.HasMoved = !onStartingSquare(piece, pos)
+		b.squares[pos.Row][pos.Col] = piece
+
+		if piece.Type == King {
+			kings[piece.Player]++
+			if piece.Player == White {
+				b.whiteKing = pos
+			} else {
+				b.blackKing = pos
+			}
+		}
+	}
+
+	for _, player := range []Player{White, Black} {
+		if kings[player] != 1 {
+			return nil, fmt.Errorf("%s must have exactly one king, found %d", player, kings[player])
+		}
+	}
+	b.deriveCastlingRights()
+	b.syncBitboards()
+	b.recordPosition()
+	return b, nil
+}
+
+// onStartingSquare reports whether the piece stands on a square it occupies
+// in the initial position
+func onStartingSquare(piece *Piece, pos Position) bool {
+	backRank, pawnRank := 7, 6
+	if piece.Player == Black {
+		backRank, pawnRank = 0, 1
+	}
+
+	switch piece.Type {
+	case Pawn:
+		return pos.Row == pawnRank
+	case Rook:
+		return pos.Row == backRank && (pos.Col == 0 || pos.Col == 7)
+	case Knight:
+		return pos.Row == backRank && (pos.Col == 1 || pos.Col == 6)
+	case Bishop:
+		return pos.Row == backRank && (pos.Col == 2 || pos.Col == 5)
+	case Queen:
+		return pos.Row == backRank && pos.Col == 3
+	case King:
+		return pos.Row == backRank && pos.Col == 4
+	}
+	return false
+}
+
+// BoardFromFEN builds a position from Forsyth-Edwards Notation. The halfmove
+// clock and fullmove number may be omitted. Castling availability and the en
+// passant target are taken from the FEN as given, and the position must pass
+// ValidatePosition.
+func BoardFromFEN(fen string) (*Board, error) {
+	fields := strings.Fields(fen)
+	if len(fields) != 4 && len(fields) != 6 {
+		return nil, fmt.Errorf("FEN must have 4 or 6 fields, found %d", len(fields))
+	}
+
+	b := &Board{}
+	kings := map[Player]int{}
+
+	// Piece placement
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return nil, fmt.Errorf("FEN piece placement must have 8 ranks, found %d", len(ranks))
+	}
+	for row, rank := range ranks {
+		col := 0
+		for i := 0; i < len(rank); i++ {
+			c := rank[i]
+			if c >= '1' && c <= '8' {
+				col += int(c - '0')
+				continue
+			}
+			piece, ok := pieceFromFENChar(c)
+			if !ok {
+				return nil, fmt.Errorf("invalid piece letter %q in FEN", c)
+			}
+			if col > 7 {
+				return nil, fmt.Errorf("FEN rank %d has more than 8 squares", 8-row)
+			}
+			pos := Position{row, col}
+			piece.HasMoved = !onStartingSquare(piece, pos)
+			b.squares[row][col] = piece
+			if piece.Type == King {
+				kings[piece.Player]++
+				if piece.Player == White {
+					b.whiteKing = pos
+				} else {
+					b.blackKing = pos
+				}
+			}
+			col++
+		}
+		if col != 8 {
+			return nil, fmt.Errorf("FEN rank %d does not have 8 squares", 8-row)
+		}
+	}
+	for _, player := range []Player{White, Black} {
+		if kings[player] != 1 {
+			return nil, fmt.Errorf("%s must have exactly one king, found %d", player, kings[player])
+		}
+	}
+
+	// Active color
+	var toMove Player
+	switch fields[1] {
+	case "w":
+		toMove = White
+	case "b":
+		toMove = Black
+	default:
+		return nil, fmt.Errorf("invalid active color %q in FEN", fields[1])
+	}
+
+	// Castling availability
+	if fields[2] != "-" {
+		for _, c := range fields[2] {
+			switch c {
+			case 'K':
+				b.castling |= WhiteKingSide
+			case 'Q':
+				b.castling |= WhiteQueenSide
+			case 'k':
+				b.castling |= BlackKingSide
+			case 'q':
+				b.castling |= BlackQueenSide
+			default:
+				return nil, fmt.Errorf("invalid castling availability %q in FEN", fields[2])
+			}
+		}
+	}
+
+	// En passant target, recreated as the pawn advance that allowed it
+	if fields[3] != "-" {
+		if err := b.setEnPassantTarget(fields[3], toMove); err != nil {
+			return nil, err
+		}
+	}
+
+	// Move counters
+	fullMove := 1
+	if len(fields) == 6 {
+		var err error
+		if b.halfMoveClock, err = strconv.Atoi(fields[4]); err != nil || b.halfMoveClock < 0 {
+			return nil, fmt.Errorf("invalid halfmove clock %q in FEN", fields[4])
+		}
+		if fullMove, err = strconv.Atoi(fields[5]); err != nil || fullMove < 1 {
+			return nil, fmt.Errorf("invalid fullmove number %q in FEN", fields[5])
+		}
+	}
+	b.moveCount = 2*(fullMove-1) + int(toMove)
+	b.syncBitboards()
+
+	if err := b.ValidatePosition(toMove); err != nil {
+		return nil, err
+	}
+	b.recordPosition()
+	return b, nil
+}
+
+// setEnPassantTarget records the two-square pawn advance implied by an en
+// passant target square, so that the capture is available to toMove
+func (b *Board) setEnPassantTarget(square string, toMove Player) error {
+	target, err := ParseSquare(square)
+	if err != nil {
+		return fmt.Errorf("invalid en passant target in FEN: %v", err)
+	}
+
+	// The opponent's pawn advanced from behind the target to in front of it
+	forward := 1 // Direction of the opponent's pawns
+	targetRow := 2
+	if toMove == Black {
+		forward = -1
+		targetRow = 5
+	}
+	from := Position{target.Row - forward, target.Col}
+	to := Position{target.Row + forward, target.Col}
+	pawn := b.squares[to.Row][to.Col]
+	if target.Row != targetRow || pawn == nil || pawn.Type != Pawn || pawn.Player == toMove ||
+		b.squares[target.Row][target.Col] != nil || b.squares[from.Row][from.Col] != nil {
+		return fmt.Errorf("en passant target %s in FEN does not follow a two-square pawn advance", target)
+	}
+
+	pawn.HasMoved = true
+	b.lastMove = Move{From: from, To: to, Piece: pawn}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import "testing"
+
+func TestFENRoundTrip(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/8/8/8/8/8/8/R3K2R b Kq - 5 20",
+		"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1",
+		"4k3/8/8/8/3Pp3/8/8/4K3 b - d3 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	}
+	for _, fen := range fens {
+		board, err := BoardFromFEN(fen)
+		if err != nil {
+			t.Errorf("%s: %v", fen, err)
+			continue
+		}
+		if got := board.ToFEN(); got != fen {
+			t.Errorf("BoardFromFEN(%q).ToFEN() = %q", fen, got)
+		}
+	}
+}
+
+func TestFENDefaults(t *testing.T) {
+	// The move counters may be left out, and an en passant target no pawn
+	// can capture on is dropped
+	tests := []struct{ fen, want string }{
+		{"4k3/8/8/8/8/8/8/4K3 w - -", "4k3/8/8/8/8/8/8/4K3 w - - 0 1"},
+		{"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1"},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Errorf("%s: %v", tt.fen, err)
+			continue
+		}
+		if got := board.ToFEN(); got != tt.want {
+			t.Errorf("BoardFromFEN(%q).ToFEN() = %q, want %q", tt.fen, got, tt.want)
+		}
+	}
+}
+
+func TestFENErrors(t *testing.T) {
+	fens := []string{
+		"",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1",           // Seven ranks
+		"rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",  // Nine squares
+		"rnbqkbnr/pppppppp/7/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",  // Seven squares
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQXBNR w KQkq - 0 1",  // Unknown piece
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQQBNR w KQkq - 0 1",  // No white king
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1",  // Side to move
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkx - 0 1",  // Castling
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e3 0 1", // No pawn advance to e3
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - -1 1", // Halfmove clock
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0",  // Fullmove number
+	}
+	for _, fen := range fens {
+		if _, err := BoardFromFEN(fen); err == nil {
+			t.Errorf("BoardFromFEN(%q) succeeded, want an error", fen)
+		}
+	}
+}
+
+func TestFENEnPassantAfterDoubleStep(t *testing.T) {
+	// The target is only written when a pawn can legally take en passant
+	tests := []struct {
+		name, fen, move, want string
+	}{
+		{"black step, white can take", "4k3/3p4/8/4P3/8/8/8/4K3 b - - 0 1", "d7-d5", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2"},
+		{"black step, no pawn beside", "4k3/3p4/8/8/4P3/8/8/4K3 b - - 0 1", "d7-d5", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 2"},
+		{"white step, black can take", "4k3/8/8/8/3p4/8/4P3/4K3 w - - 0 1", "e2-e4", "4k3/8/8/8/3pP3/8/8/4K3 b - e3 0 1"},
+		// Taking would clear the rank between the rook and the black king
+		{"white step, capture exposes the king", "8/8/8/8/k2p3R/8/4P3/4K3 w - - 0 1", "e2-e4", "8/8/8/8/k2pP2R/8/8/4K3 b - - 0 1"},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		playMove(t, board, tt.move)
+		if got := board.ToFEN(); got != tt.want {
+			t.Errorf("%s: %s reached %s, want %s", tt.name, tt.move, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FIFOSource plays moves written to a named pipe by another program, such
+// as an analysis script, one per line in coordinate notation like a script.
+// Writers may come and go: when one closes the pipe, the next move waits
+// for another to open it. Illegal moves are reported and skipped, so a
+// faulty tool can't end the game.
+type FIFOSource struct {
+	path    string
+	file    *os.File
+	scanner *bufio.Scanner
+}
+
+// NewFIFOSource checks that path is a named pipe, as made by mkfifo. The
+// pipe is opened when the first move is needed, since opening it blocks
+// until a writer appears.
+func NewFIFOSource(path string) (*FIFOSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return nil, fmt.Errorf("%s is not a named pipe (create one with mkfifo)", path)
+	}
+	return &FIFOSource{path: path}, nil
+}
+
+func (f *FIFOSource) NextMove(g *Game) (Move, error) {
+	player := g.Board.ToMove()
+	fmt.Printf("\nWaiting for %s's move from %s...\n", player, f.path)
+	for {
+		if f.scanner == nil {
+			// Blocks until a writer opens the pipe
+			file, err := os.Open(f.path)
+			if err != nil {
+				return Move{}, err
+			}
+			f.file, f.scanner = file, bufio.NewScanner(file)
+		}
+		if !f.scanner.Scan() {
+			// The writer closed the pipe; wait for the next one
+			err := f.scanner.Err()
+			f.file.Close()
+			f.file, f.scanner = nil, nil
+			if err != nil {
+				return Move{}, err
+			}
+			continue
+		}
+
+		line := cleanLine(f.scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		move, err := parseMoveLine(g.Board, line)
+		if err == nil {
+			if _, err = g.Board.ResolveMove(move, player); err != nil {
+				err = fmt.Errorf("%q: %v", line, err)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring move from %s: %v\n", f.path, err)
+			continue
+		}
+		return move, nil
+	}
+}
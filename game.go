@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Game holds the state of a single game: the board and the moves played
+type Game struct {
+	ID    int
+	Start string // FEN of the starting position
+	Board *Board
+	Moves []Move
+	Rules Rules // Optional rules in force; the board follows them too
+
+	ended       GameResult     // Set by resignation or an agreed or claimed draw
+	captureSeen [King + 1]bool // Piece types captured so far, for -coach
+	clocks      [2]moveClock   // Time each side spent on its moves
+	clocksAt    [][2]moveClock // Clocks before each move, restored by Undo
+	openings    []ecoEntry     // Opening reached by each move, for ECO
+
+	// Move history lines formatted so far, and how many moves they cover
+	history      []string
+	historyMoves int
+}
+
+func NewGame() *Game {
+	return newGame(NewBoard())
+}
+
+// NewGameFromFEN starts a game from the given position
+func NewGameFromFEN(fen string) (*Game, error) {
+	board, err := BoardFromFEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	return newGame(board), nil
+}
+
+// newGame starts a game from board under gameRules
+func newGame(board *Board) *Game {
+	g := &Game{Start: board.ToFEN(), Board: board, Moves: make([]Move, 0), Rules: gameRules}
+	board.rules = &g.Rules
+	return g
+}
+
+// Play applies a move for the side to move and records it in the history
+func (g *Game) Play(move Move) error {
+	if err := g.Board.Apply(move, g.Board.ToMove()); err != nil {
+		return err
+	}
+	played := g.Board.lastMove
+	played.Comment = move.Comment
+	g.Moves = append(g.Moves, played)
+	g.clocksAt = append(g.clocksAt, g.clocks)
+	g.openings = append(g.openings, g.openingAfterMove())
+	return nil
+}
+
+// clone returns a copy of the game that moves can be played on and taken
+// back without affecting g
+func (g *Game) clone() *Game {
+	c := *g
+	c.Board = g.Board.Snapshot()
+	c.Moves = slices.Clone(g.Moves)
+	c.clocksAt = slices.Clone(g.clocksAt)
+	c.openings = slices.Clone(g.openings)
+	c.history, c.historyMoves = nil, 0
+	return &c
+}
+
+// Undo takes back the last move played, restoring the board exactly and
+// the time each side had spent before it
+func (g *Game) Undo() error {
+	if len(g.Moves) == 0 {
+		return fmt.Errorf("no moves to undo")
+	}
+	move := g.Moves[len(g.Moves)-1]
+	g.Board.forgetPosition()
+	g.Board.undoMove(move)
+	g.Moves = g.Moves[:len(g.Moves)-1]
+	if move.Captured != nil {
+		// The coach notes the type again if no earlier move captured one
+		g.captureSeen[move.Captured.Type] = slices.ContainsFunc(g.Moves, func(m Move) bool {
+			return m.Captured != nil && m.Captured.Type == move.Captured.Type
+		})
+	}
+	g.clocks = g.clocksAt[len(g.clocksAt)-1]
+	g.clocksAt = g.clocksAt[:len(g.clocksAt)-1]
+	g.openings = g.openings[:len(g.openings)-1]
+	g.history, g.historyMoves = nil, 0
+	return nil
+}
+
+// LastMoveSAN returns the last move played in SAN, or "" if there is none
+func (g *Game) LastMoveSAN() string {
+	if len(g.Moves) == 0 {
+		return ""
+	}
+	// SAN needs the position before the move, so step back and replay it
+	move := g.Board.lastMove
+	g.Board.undoMove(move)
+	san := g.Board.SAN(move)
+	g.Board.makeMove(move)
+	return san
+}
+
+// Resign ends the game with player conceding
+func (g *Game) Resign(player Player) {
+	g.ended = GameResult{Reason: Resignation, Winner: 1 - player}
+}
+
+// Draw ends the game in a draw agreed by the players or claimed under
+// reason
+func (g *Game) Draw(reason ResultReason) {
+	g.ended = GameResult{Reason: reason}
+}
+
+// Result returns how the game ended, or a result with reason InProgress
+// while it goes on
+func (g *Game) Result() GameResult {
+	if g.ended.IsOver() {
+		return g.ended
+	}
+	return g.Board.Result()
+}
+
+// Outcome describes how the game ended, or returns "" while it is in progress
+func (g *Game) Outcome() string {
+	return g.Result().Message()
+}
+
+// Render displays the move history and the board, side by side with the
+// captured pieces when the terminal is wide enough
+func (g *Game) Render() {
+	board := g.Board
+
+	if *noClearFlag {
+		fmt.Printf("\n[%s]\n", board.Summary())
+	} else {
+		ClearScreen()
+	}
+
+	// Draw the board, from the mover's side in hotseat games
+	opts := renderOptions
+	if *hotseatFlipFlag {
+		opts.Flipped = board.ToMove() == Black
+	}
+	drawn := board.DrawString(opts)
+
+	opening := ""
+	if code, name := g.ECO(); code != "" {
+		opening = fmt.Sprintf("Opening: %s %s", code, name)
+	}
+
+	if width, ok := terminalWidth(); ok {
+		panel := append([]string{"Move History:"}, g.recentHistory()...)
+		if opening != "" {
+			panel = append(panel, "", opening)
+		}
+		panel = append(panel, "", "Captured:")
+		panel = append(panel, g.capturedLines()...)
+		if layout, ok := besideBoard(drawn, panel, width); ok {
+			fmt.Print("\n" + layout)
+			if *evalBarFlag {
+				board.DrawEvalBar(2)
+			}
+			return
+		}
+	}
+
+	// Display move history
+	fmt.Println("\nMove History:")
+	fmt.Print(strings.Join(g.recentHistory(), "\n"))
+	fmt.Print("\n\n")
+	if opening != "" {
+		fmt.Printf("%s\n\n", opening)
+	}
+
+	fmt.Print(drawn)
+	if *evalBarFlag {
+		board.DrawEvalBar(2)
+	}
+}
+
+// historyLines returns the moves played, one line per move number. Numbers
+// continue from the starting position, so a game set up with Black to move
+// at move 10 starts with "10... ". Lines are kept between calls and only
+// moves played since are formatted, so long games don't slow every frame.
+func (g *Game) historyLines() []string {
+	ply := g.Board.moveCount - len(g.Moves)
+	for i := g.historyMoves; i < len(g.Moves); i++ {
+		move := g.Moves[i]
+		number := (ply+i)/2 + 1
+		text := historyMove(move)
+		switch {
+		case (ply+i)%2 == 0:
+			g.history = append(g.history, fmt.Sprintf("%d. %s", number, text))
+		case i == 0:
+			g.history = append(g.history, fmt.Sprintf("%d... %s", number, text))
+		default:
+			g.history[len(g.history)-1] += " " + text
+		}
+	}
+	g.historyMoves = len(g.Moves)
+	return g.history
+}
+
+// historyMove formats a move for the history. With -show-captures, captures
+// are written with an "x" and the captured piece, e.g. "e4xd5 (♟)"; en
+// passant shows the pawn taken even though the target square was empty.
+func historyMove(move Move) string {
+	if !*showCapturesFlag || move.Captured == nil {
+		return move.String()
+	}
+	text := move.From.String() + "x" + move.To.String()
+	if move.Promotion != Pawn {
+		text += string(pieceLetters[move.Promotion])
+	}
+	return fmt.Sprintf("%s (%s)", text, move.Captured)
+}
+
+// recentHistory returns the history lines to display: the last -history
+// move numbers, after a line counting those left out
+func (g *Game) recentHistory() []string {
+	lines := g.historyLines()
+	if *historyFlag <= 0 || len(lines) <= *historyFlag {
+		return lines
+	}
+	hidden := len(lines) - *historyFlag
+	note := fmt.Sprintf("(%d earlier moves)", hidden)
+	if hidden == 1 {
+		note = "(1 earlier move)"
+	}
+	return append([]string{note}, lines[hidden:]...)
+}
+
+// capturedLines lists the pieces each side has captured
+func (g *Game) capturedLines() []string {
+	var captured [2]string
+	for _, move := range g.Moves {
+		if move.Captured != nil {
+			captured[move.Piece.Player] += move.Captured.String()
+		}
+	}
+	return []string{"White: " + captured[White], "Black: " + captured[Black]}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGameUndoRestoresState(t *testing.T) {
+	g := NewGame()
+	type snapshot struct {
+		board  boardState
+		clocks [2]moveClock
+	}
+	var before []snapshot
+	for i, notation := range []string{"e2-e4", "e7-e5", "g1-f3", "b8-c6", "e1-e2"} {
+		before = append(before, snapshot{stateOf(g.Board), g.clocks})
+		m, err := ParseCoordinateMove(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		player := g.Board.ToMove()
+		if err := g.Play(m); err != nil {
+			t.Fatalf("%s: %v", notation, err)
+		}
+		// As the manager does, charge the mover the time it took
+		g.clocks[player].total += time.Duration(i+1) * time.Second
+		g.clocks[player].moves++
+	}
+
+	for i := len(before) - 1; i >= 0; i-- {
+		if err := g.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		checkState(t, "undo", stateOf(g.Board), before[i].board)
+		if g.clocks != before[i].clocks {
+			t.Errorf("undo to move %d: clocks %v, want %v", i, g.clocks, before[i].clocks)
+		}
+		if len(g.Moves) != i {
+			t.Errorf("undo to move %d: %d moves left", i, len(g.Moves))
+		}
+	}
+	if err := g.Undo(); err == nil {
+		t.Error("Undo with no moves succeeded")
+	}
+}
+
+func TestPromotion(t *testing.T) {
+	// Every promotion resets the halfmove clock, captures included, and
+	// undoing it restores the pawn, the captured piece and the clock
+	white := "r3k3/1P6/8/8/8/8/8/4K3 w - - 17 40"
+	tests := []struct {
+		fen, move, san, after string
+	}{
+		{white, "b7-b8q", "b8=Q+", "rQ2k3/8/8/8/8/8/8/4K3 b - - 0 40"},
+		{white, "b7-b8r", "b8=R+", "rR2k3/8/8/8/8/8/8/4K3 b - - 0 40"},
+		{white, "b7-b8b", "b8=B", "rB2k3/8/8/8/8/8/8/4K3 b - - 0 40"},
+		{white, "b7-b8n", "b8=N", "rN2k3/8/8/8/8/8/8/4K3 b - - 0 40"},
+		{white, "b7-a8q", "bxa8=Q+", "Q3k3/8/8/8/8/8/8/4K3 b - - 0 40"},
+		{white, "b7-a8r", "bxa8=R+", "R3k3/8/8/8/8/8/8/4K3 b - - 0 40"},
+		{white, "b7-a8b", "bxa8=B", "B3k3/8/8/8/8/8/8/4K3 b - - 0 40"},
+		{white, "b7-a8n", "bxa8=N", "N3k3/8/8/8/8/8/8/4K3 b - - 0 40"},
+		// Taking the rook on its home square also takes away castling
+		{"4k3/8/8/8/8/8/6p1/4K2R b K - 9 30", "g2-h1q", "gxh1=Q+", "4k3/8/8/8/8/8/8/4K2q w - - 0 31"},
+		{"4k3/8/8/8/8/8/6p1/4K2R b K - 9 30", "g2-h1n", "gxh1=N", "4k3/8/8/8/8/8/8/4K2n w - - 0 31"},
+		{"4k3/8/8/8/8/8/6p1/4K2R b K - 9 30", "g2-g1r", "g1=R+", "4k3/8/8/8/8/8/8/4K1rR w K - 0 31"},
+	}
+	for _, tt := range tests {
+		g, err := NewGameFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		m, err := ParseCoordinateMove(tt.move)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.move, err)
+		}
+		if err := g.Play(m); err != nil {
+			t.Fatalf("%s: %s: %v", tt.fen, tt.move, err)
+		}
+		if got := g.LastMoveSAN(); got != tt.san {
+			t.Errorf("%s: %s written as %s, want %s", tt.fen, tt.move, got, tt.san)
+		}
+		if got := g.Board.ToFEN(); got != tt.after {
+			t.Errorf("%s: %s reached %s, want %s", tt.fen, tt.move, got, tt.after)
+		}
+		if err := g.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		if got := g.Board.ToFEN(); got != tt.fen {
+			t.Errorf("%s: undoing %s reached %s", tt.fen, tt.move, got)
+		}
+	}
+}
+
+// BenchmarkHistory5000 measures drawing the move history of a 5000-ply
+// game: formatting it all, as a redraw did before the lines were kept, and
+// a redraw with the lines kept from the frame before, in full and with
+// -history 20
+func BenchmarkHistory5000(b *testing.B) {
+	g := NewGame()
+	g.Rules.Threefold = false
+	shuffle := []Move{
+		{From: Position{7, 6}, To: Position{5, 5}, Promotion: Pawn},
+		{From: Position{0, 6}, To: Position{2, 5}, Promotion: Pawn},
+		{From: Position{5, 5}, To: Position{7, 6}, Promotion: Pawn},
+		{From: Position{2, 5}, To: Position{0, 6}, Promotion: Pawn},
+	}
+	for len(g.Moves) < 5000 {
+		if err := g.Play(shuffle[len(g.Moves)%len(shuffle)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("rebuild", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			g.history, g.historyMoves = nil, 0
+			g.recentHistory()
+		}
+	})
+	b.Run("kept", func(b *testing.B) {
+		b.ReportAllocs()
+		g.recentHistory()
+		for i := 0; i < b.N; i++ {
+			g.recentHistory()
+		}
+	})
+	b.Run("kept, last 20", func(b *testing.B) {
+		defer func() { *historyFlag = 0 }()
+		*historyFlag = 20
+		b.ReportAllocs()
+		g.recentHistory()
+		for i := 0; i < b.N; i++ {
+			g.recentHistory()
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package main
+
+import "sort"
+
+// gradeDepth is how many plies the grade command searches after each move:
+// enough to see the reply that wins a hung piece, quick enough for any move
+const gradeDepth = 2
+
+// blunderMargin is how far, in centipawns, a move must score below the best
+// one to be marked a blunder
+const blunderMargin = 200
+
+// GradedMove is a legal move with its score for the side playing it
+type GradedMove struct {
+	Move  Move
+	SAN   string
+	Score int
+}
+
+// GradeMoves scores every legal move for the side to move with a shallow
+// search of depth plies after it and returns them best first. The board is
+// left unchanged.
+func (b *Board) GradeMoves(depth int) []GradedMove {
+	var graded []GradedMove
+	for _, move := range b.LegalMoves(b.ToMove()) {
+		san := b.SAN(move)
+		var line []Move
+		b.makeMove(move)
+		score := -b.negamax(evaluator, max(depth, 1)-1, 1, -mateScore-1, mateScore+1, &line)
+		b.undoMove(move)
+		graded = append(graded, GradedMove{Move: move, SAN: san, Score: score})
+	}
+	sort.SliceStable(graded, func(i, j int) bool {
+		if graded[i].Score != graded[j].Score {
+			return graded[i].Score > graded[j].Score
+		}
+		return graded[i].SAN < graded[j].SAN
+	})
+	return graded
+}
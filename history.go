@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// historyPageLines is how many lines the history command shows at a time
+const historyPageLines = 20
+
+// SANHistory returns the moves of the game in SAN, one line per move
+// number, e.g. "12. Nf3 Nc6". With withCaptures, captures are followed by
+// the captured piece, e.g. "Nxe5 (♟)".
+func (g *Game) SANHistory(withCaptures bool) []string {
+	board, err := BoardFromFEN(g.Start)
+	if err != nil {
+		return nil
+	}
+
+	var lines []string
+	for i, m := range g.Moves {
+		move, err := board.ResolveMove(m, board.ToMove())
+		if err != nil {
+			break
+		}
+		token := board.SAN(move)
+		if withCaptures && move.Captured != nil {
+			token += fmt.Sprintf(" (%s)", move.Captured)
+		}
+
+		number := board.moveCount/2 + 1
+		switch {
+		case board.ToMove() == White:
+			lines = append(lines, fmt.Sprintf("%d. %s", number, token))
+		case i == 0:
+			lines = append(lines, fmt.Sprintf("%d... %s", number, token))
+		default:
+			lines[len(lines)-1] += " " + token
+		}
+		board.makeMove(move)
+	}
+	return lines
+}
+
+// showHistory prints the last N move numbers of the game in SAN, or all of
+// them, a page at a time
+func (h *HumanSource) showHistory(g *Game, args []string) {
+	lines := g.SANHistory(*showCapturesFlag)
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Printf("Error: invalid number of moves %q\n", args[0])
+			h.pause()
+			return
+		}
+		lines = lines[max(len(lines)-n, 0):]
+	}
+
+	fmt.Println()
+	if len(lines) == 0 {
+		fmt.Println("No moves played yet.")
+	}
+	for start := 0; start < len(lines); start += historyPageLines {
+		end := min(start+historyPageLines, len(lines))
+		fmt.Println(strings.Join(lines[start:end], "\n"))
+		if end == len(lines) {
+			break
+		}
+		fmt.Print("-- more: Enter for the next page, q to stop -- ")
+		if !h.scanner.Scan() || strings.TrimSpace(h.scanner.Text()) == "q" {
+			return
+		}
+	}
+	h.pause()
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+)
+
+// HotseatPause hides the board between turns when two players share one
+// screen, waiting until the next player has the device
+type HotseatPause struct {
+	scanner *bufio.Scanner
+}
+
+// MoveMade clears the screen and waits for the next player to press Enter
+func (h *HotseatPause) MoveMade(g *Game, move Move) {
+	if g.Outcome() != "" {
+		return
+	}
+	if !*noClearFlag {
+		ClearScreen()
+	}
+	fmt.Printf("\nPass the device to %s, then press Enter...", g.Board.ToMove())
+	h.scanner.Scan()
+}
@@ -0,0 +1,38 @@
+package main
+
+// imbalance scores the material imbalances that piece values alone miss,
+// from player's point of view: the bishop pair (bishops on both square
+// colours), knights against bishops depending on how blocked the pawns
+// are, and two minor pieces against a rook. Equal armies score zero.
+func (b *Board) imbalance(player Player) int {
+	var count [2][King + 1]int
+	for pt := Pawn; pt <= King; pt++ {
+		count[White][pt] = b.PieceCount(White, pt)
+		count[Black][pt] = b.PieceCount(Black, pt)
+	}
+	mine, theirs := count[player], count[1-player]
+	pawns := mine[Pawn] + theirs[Pawn]
+
+	score := 0
+	if b.HasBishopPair(player) {
+		score += evalWeights.BishopPair
+	}
+	if b.HasBishopPair(1 - player) {
+		score -= evalWeights.BishopPair
+	}
+
+	// Knights like closed positions full of pawns, bishops open ones
+	knightsOverBishops := (mine[Knight] - mine[Bishop]) - (theirs[Knight] - theirs[Bishop])
+	score += evalWeights.KnightBishop * knightsOverBishops * (pawns - 8) / 8
+
+	// Two minor pieces usually outplay a rook while there are pieces left
+	minors := mine[Knight] + mine[Bishop] - theirs[Knight] - theirs[Bishop]
+	rooks := mine[Rook] - theirs[Rook]
+	switch {
+	case minors >= 2 && rooks <= -1:
+		score += evalWeights.RookMinors
+	case minors <= -2 && rooks >= 1:
+		score -= evalWeights.RookMinors
+	}
+	return score
+}
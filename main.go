@@ -2,9 +2,15 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"math/rand"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 )
 
 type Player int
@@ -21,11 +27,18 @@ func (p *Piece) String() string {
 }
 
 type Board struct {
-	squares   [8][8]*Piece
-	lastMove  Move // Track last move for en passant
-	moveCount int
-	whiteKing Position
-	blackKing Position
+	squares       [8][8]*Piece
+	lastMove      Move // Track last move for en passant
+	moveCount     int
+	halfMoveClock int // Plies since the last pawn move or capture
+	castling      CastlingRights
+	whiteKing     Position
+	blackKing     Position
+	// Occurrences of each position reached by real moves, keyed by PositionKey
+	positionCounts map[string]int
+	nodes          int    // Positions visited by the last search
+	rules          *Rules // Rules of the game the board belongs to; nil for StandardRules
+	bb             bitboards
 }
 
 type Move struct {
@@ -35,12 +48,34 @@ type Move struct {
 	Captured    *Piece
 	IsEnPassant bool
 	IsCastling  bool
+	Promotion   PieceType // Piece a pawn reaching the last rank becomes; Pawn if none
+	Comment     string    // Player annotation, emitted in PGN as {...}
+	// State before the move was made, restored on undo
+	HalfMoveClock int
+	Castling      CastlingRights
+	HadMoved      bool
+	RookHadMoved  bool // For castling moves
+	prevLastMove  *Move
 }
 
 type Position struct {
 	Row, Col int
 }
 
+// String returns the move in the coordinate notation used for input, e.g. "e2-e4"
+func (m Move) String() string {
+	s := m.From.String() + "-" + m.To.String()
+	if m.Promotion != Pawn {
+		s += string(pieceLetters[m.Promotion])
+	}
+	return s
+}
+
+// String returns the position in algebraic notation, e.g. "e4"
+func (p Position) String() string {
+	return string([]byte{byte('a' + p.Col), byte('8' - p.Row)})
+}
+
 const (
 	White Player = iota
 	Black
@@ -64,6 +99,29 @@ const (
 	King
 )
 
+var pieceNames = map[PieceType]string{
+	Pawn:   "pawn",
+	Rook:   "rook",
+	Knight: "knight",
+	Bishop: "bishop",
+	Queen:  "queen",
+	King:   "king",
+}
+
+func (pt PieceType) String() string {
+	return pieceNames[pt]
+}
+
+// pieceLetters holds the FEN/SAN letter for each piece type (lowercase)
+var pieceLetters = map[PieceType]byte{
+	Pawn:   'p',
+	Rook:   'r',
+	Knight: 'n',
+	Bishop: 'b',
+	Queen:  'q',
+	King:   'k',
+}
+
 var pieceIcons = map[PieceType]string{
 	Pawn:   "♙♟",
 	Rook:   "♖♜",
@@ -94,68 +152,125 @@ func NewBoard() *Board {
 	// Store initial king positions
 	b.whiteKing = Position{7, 4}
 	b.blackKing = Position{0, 4}
+	b.castling = AllCastlingRights
+	b.syncBitboards()
+	b.recordPosition()
 	return b
 }
 
 func (b *Board) Draw() {
-	fmt.Println("   a b c d e f g h")
-	fmt.Println("  ─────────────────")
-	for row := 0; row < 8; row++ {
-		fmt.Printf("%d│ ", 8-row)
-		for col := 0; col < 8; col++ {
-			if b.squares[row][col] == nil {
-				fmt.Print(". ")
-			} else {
-				fmt.Print(b.squares[row][col], " ")
-			}
+	fmt.Print(b.DrawString(renderOptions))
+}
+
+// evalBarLimit is the score in centipawns at which the evaluation bar is full
+const evalBarLimit = 1000
+
+// DrawEvalBar prints a horizontal evaluation bar under the board, filled
+// from the left in White's favour. Forced mates within mateDepth moves are
+// reported as "mate in N" instead of a score.
+func (b *Board) DrawEvalBar(mateDepth int) {
+	const width = 17
+
+	score := b.Evaluate(White)
+	label := fmt.Sprintf("%+.2f", float64(score)/100)
+	if n, ok := b.MateIn(mateDepth); ok {
+		label = fmt.Sprintf("%s mates in %d", b.ToMove(), n)
+		score = evalBarLimit
+		if b.ToMove() == Black {
+			score = -evalBarLimit
 		}
-		fmt.Printf("│%d\n", 8-row)
 	}
 
-	fmt.Println("  ─────────────────")
-	fmt.Println("   a b c d e f g h")
+	score = max(-evalBarLimit, min(evalBarLimit, score))
+	filled := (score + evalBarLimit) * width / (2 * evalBarLimit)
+	fmt.Printf("  %s%s %s\n", strings.Repeat("█", filled), strings.Repeat("░", width-filled), label)
+}
+
+// Summary returns a one-line description of the position for logs, e.g.
+// "move 14 (Black to play) — White: +2, in check: no"
+func (b *Board) Summary() string {
+	player := b.ToMove()
+
+	material := "material: even"
+	if pawns := b.Material(White) / 100; pawns > 0 {
+		material = fmt.Sprintf("White: +%d", pawns)
+	} else if pawns < 0 {
+		material = fmt.Sprintf("Black: +%d", -pawns)
+	}
+
+	check := "no"
+	if b.IsInCheck(player) {
+		check = "yes"
+	}
+
+	return fmt.Sprintf("move %d (%s to play) — %s, %s, in check: %s", b.moveCount/2+1, player, b.Phase(), material, check)
 }
 
+// Move moves a piece for currentPlayer, promoting pawns to a queen
 func (b *Board) Move(oldPos, newPos Position, currentPlayer Player) error {
+	return b.Apply(Move{From: oldPos, To: newPos}, currentPlayer)
+}
+
+// CheckMove validates a move for currentPlayer, including that it doesn't
+// leave their king in check, and returns it without changing the board
+func (b *Board) CheckMove(oldPos, newPos Position, currentPlayer Player) (Move, error) {
 	piece := b.squares[oldPos.Row][oldPos.Col]
 	if piece == nil {
-		return fmt.Errorf("no piece at source position")
+		return Move{}, fmt.Errorf("no piece at source position")
 	}
 	if piece.Player != currentPlayer {
-		return fmt.Errorf("it's not your turn")
+		return Move{}, fmt.Errorf("it's not your turn")
 	}
 
 	// Check if the move is valid
 	move, err := b.ValidateMove(oldPos, newPos, currentPlayer)
 	if err != nil {
-		return err
+		return move, err
 	}
 
-	// Make the move
+	// Check if the move puts the current player in check. This is tested on
+	// the board after the move, so a king capturing a defended piece is
+	// caught, including by a defender x-raying through the captured piece.
+	// Likewise a king can't hide from a rook on e1 by stepping to f1: it has
+	// left e1, so it no longer blocks the rook's ray to f1. IsInCheck walks
+	// the rook's ray over the occupancy bitboards, and makeMove has already
+	// cleared e1 in them through setSquare, so the ray runs on to f1.
 	b.makeMove(move)
-
-	// Check if the move puts the current player in check
-	if b.IsInCheck(currentPlayer) {
-		b.undoMove(move)
-		return fmt.Errorf("move would leave king in check")
+	inCheck := b.IsInCheck(currentPlayer)
+	b.undoMove(move)
+	if inCheck {
+		return move, fmt.Errorf("move would leave king in check")
 	}
 
-	return nil
+	return move, nil
+}
+
+// isLegalMove reports whether player may move from oldPos to newPos without
+// leaving their own king in check. The board is left unchanged.
+func (b *Board) isLegalMove(oldPos, newPos Position, player Player) bool {
+	_, err := b.CheckMove(oldPos, newPos, player)
+	return err == nil
 }
 
 func (b *Board) ValidateMove(oldPos, newPos Position, currentPlayer Player) (Move, error) {
 	piece := b.squares[oldPos.Row][oldPos.Col]
 	move := Move{
-		From:     oldPos,
-		To:       newPos,
-		Piece:    piece,
-		Captured: b.squares[newPos.Row][newPos.Col],
+		From:          oldPos,
+		To:            newPos,
+		Piece:         piece,
+		HalfMoveClock: b.halfMoveClock,
+		Castling:      b.castling,
+		HadMoved:      piece.HasMoved,
 	}
 
-	// Basic validation
+	// Basic validation, before the destination square is looked up
 	if !isValidPosition(newPos) {
 		return move, fmt.Errorf("destination position is outside the board")
 	}
+	if newPos == oldPos {
+		return move, fmt.Errorf("a piece must move to a different square")
+	}
+	move.Captured = b.squares[newPos.Row][newPos.Col]
 
 	if move.Captured != nil && move.Captured.Player == currentPlayer {
 		return move, fmt.Errorf("cannot capture your own piece")
@@ -166,6 +281,17 @@ func (b *Board) ValidateMove(oldPos, newPos Position, currentPlayer Player) (Mov
 		return move, fmt.Errorf("invalid move for %s", piece)
 	}
 
+	// Pawns reaching the last rank promote, to a queen unless chosen otherwise
+	if piece.Type == Pawn && (newPos.Row == 0 || newPos.Row == 7) {
+		move.Promotion = Queen
+	}
+
+	// Remember the previous move so undo can restore en passant state. Its
+	// own link is dropped to avoid chaining the whole game history.
+	prev := b.lastMove
+	prev.prevLastMove = nil
+	move.prevLastMove = &prev
+
 	return move, nil
 }
 
@@ -240,6 +366,7 @@ func (b *Board) validatePawnMove(piece *Piece, oldPos, newPos Position, dr, dc i
 		// En passant
 		if b.canEnPassant(oldPos, newPos, piece.Player) {
 			move.IsEnPassant = true
+			move.Captured = b.squares[oldPos.Row][newPos.Col]
 			return true
 		}
 	}
@@ -248,12 +375,8 @@ func (b *Board) validatePawnMove(piece *Piece, oldPos, newPos Position, dr, dc i
 }
 
 func (b *Board) validateCastling(piece *Piece, oldPos, newPos Position, move *Move) bool {
-	if piece.HasMoved {
-		return false
-	}
-
 	// Check if it's a castling move
-	if oldPos.Row != newPos.Row || abs(newPos.Col-oldPos.Col) != 2 {
+	if !b.Rules().Castling || oldPos.Row != newPos.Row || abs(newPos.Col-oldPos.Col) != 2 {
 		return false
 	}
 
@@ -264,13 +387,17 @@ func (b *Board) validateCastling(piece *Piece, oldPos, newPos Position, move *Mo
 		rookCol = 0
 	}
 
-	// Check if rook is in place and hasn't moved
+	// Check if the castling right is still held and the rook is in place
+	if !b.CanCastle(piece.Player, isKingSide) {
+		return false
+	}
 	rook := b.squares[row][rookCol]
-	if rook == nil || rook.Type != Rook || rook.HasMoved {
+	if rook == nil || rook.Type != Rook || rook.Player != piece.Player {
 		return false
 	}
 
-	// Check if path is clear
+	// Check if every square between king and rook is empty, including b1/b8
+	// for queenside castling
 	startCol := min(oldPos.Col, rookCol) + 1
 	endCol := max(oldPos.Col, rookCol)
 	for col := startCol; col < endCol; col++ {
@@ -284,29 +411,37 @@ func (b *Board) validateCastling(piece *Piece, oldPos, newPos Position, move *Mo
 		return false
 	}
 
-	// Check intermediate square
+	// Check the square the king passes (f or d); IsInCheck looks at the
+	// tracked king square, so it has to follow the king. The destination (g
+	// or c) is checked by CheckMove after the move, like any king move. The
+	// rook's b-file square only has to be empty, it may be attacked.
 	intermediateCol := oldPos.Col + sign(newPos.Col-oldPos.Col)
-	b.squares[row][intermediateCol] = piece
-	b.squares[oldPos.Row][oldPos.Col] = nil
+	kingPos := &b.whiteKing
+	if piece.Player == Black {
+		kingPos = &b.blackKing
+	}
+	b.setSquare(oldPos, nil)
+	b.setSquare(Position{row, intermediateCol}, piece)
+	*kingPos = Position{row, intermediateCol}
 	inCheck := b.IsInCheck(piece.Player)
-	b.squares[oldPos.Row][oldPos.Col] = piece
-	b.squares[row][intermediateCol] = nil
+	*kingPos = oldPos
+	b.setSquare(Position{row, intermediateCol}, nil)
+	b.setSquare(oldPos, piece)
 
 	if inCheck {
 		return false
 	}
 
 	move.IsCastling = true
+	move.RookHadMoved = rook.HasMoved
 	return true
 }
 
 func (b *Board) canEnPassant(oldPos, newPos Position, player Player) bool {
-	if b.lastMove.Piece == nil || b.lastMove.Piece.Type != Pawn {
-		return false
-	}
-
-	// Check if the last move was a two-square pawn advance
-	if abs(b.lastMove.From.Row-b.lastMove.To.Row) != 2 {
+	// Only the pawn that just advanced two squares can be taken, and only
+	// on the very next ply: any other move replaces lastMove
+	target, ok := b.enPassantTarget()
+	if !ok || newPos != target || !b.Rules().EnPassant {
 		return false
 	}
 
@@ -315,12 +450,17 @@ func (b *Board) canEnPassant(oldPos, newPos Position, player Player) bool {
 	if player == Black {
 		correctRank = 4
 	}
-	if oldPos.Row != correctRank {
-		return false
-	}
+	return oldPos.Row == correctRank && b.lastMove.Piece.Player != player
+}
 
-	// Check if the captured pawn is adjacent
-	return b.lastMove.To.Col == newPos.Col && b.lastMove.To.Row == oldPos.Row
+// enPassantTarget returns the square skipped by a pawn's two-square advance
+// on the previous ply, the only square an en passant capture can land on
+func (b *Board) enPassantTarget() (Position, bool) {
+	last := b.lastMove
+	if last.Piece == nil || last.Piece.Type != Pawn || abs(last.From.Row-last.To.Row) != 2 {
+		return Position{}, false
+	}
+	return Position{(last.From.Row + last.To.Row) / 2, last.To.Col}, true
 }
 
 func (b *Board) makeMove(move Move) {
@@ -337,19 +477,25 @@ func (b *Board) makeMove(move Move) {
 		}
 		// Move rook
 		rook := b.squares[move.From.Row][rookFromCol]
-		b.squares[move.From.Row][rookToCol] = rook
-		b.squares[move.From.Row][rookFromCol] = nil
+		b.setSquare(Position{move.From.Row, rookFromCol}, nil)
+		b.setSquare(Position{move.From.Row, rookToCol}, rook)
 		rook.HasMoved = true
 	}
 
 	// Handle en passant
 	if move.IsEnPassant {
-		b.squares[move.From.Row][move.To.Col] = nil // Remove captured pawn
+		b.setSquare(Position{move.From.Row, move.To.Col}, nil) // Remove captured pawn
 	}
 
-	// Move piece
-	b.squares[move.To.Row][move.To.Col] = move.Piece
-	b.squares[move.From.Row][move.From.Col] = nil
+	// Move piece, replacing a promoting pawn; undo puts the pawn back
+	if move.Promotion != Pawn {
+		promoted := NewPiece(move.Promotion, move.Piece.Player)
+		promoted.HasMoved = true
+		b.setSquare(move.To, promoted)
+	} else {
+		b.setSquare(move.To, move.Piece)
+	}
+	b.setSquare(move.From, nil)
 
 	// Update king position if king was moved
 	if move.Piece.Type == King {
@@ -360,6 +506,15 @@ func (b *Board) makeMove(move Move) {
 		}
 	}
 
+	b.updateCastlingRights(move)
+
+	// Update the fifty-move counter
+	if move.Piece.Type == Pawn || move.Captured != nil {
+		b.halfMoveClock = 0
+	} else {
+		b.halfMoveClock++
+	}
+
 	// Store last move for en passant
 	b.lastMove = move
 	b.moveCount++
@@ -367,11 +522,11 @@ func (b *Board) makeMove(move Move) {
 
 func (b *Board) undoMove(move Move) {
 	// Restore piece to original position
-	b.squares[move.From.Row][move.From.Col] = move.Piece
-	b.squares[move.To.Row][move.To.Col] = move.Captured
+	b.setSquare(move.From, move.Piece)
+	b.setSquare(move.To, move.Captured)
 
 	// Restore HasMoved status
-	move.Piece.HasMoved = false
+	move.Piece.HasMoved = move.HadMoved
 
 	// Handle castling undo
 	if move.IsCastling {
@@ -382,15 +537,16 @@ func (b *Board) undoMove(move Move) {
 			rookToCol = 7
 		}
 		rook := b.squares[move.From.Row][rookFromCol]
-		b.squares[move.From.Row][rookToCol] = rook
-		b.squares[move.From.Row][rookFromCol] = nil
-		rook.HasMoved = false
+		b.setSquare(Position{move.From.Row, rookFromCol}, nil)
+		b.setSquare(Position{move.From.Row, rookToCol}, rook)
+		rook.HasMoved = move.RookHadMoved
 	}
 
 	// Handle en passant undo
 	if move.IsEnPassant {
 		capturedPawnRow := move.From.Row
-		b.squares[capturedPawnRow][move.To.Col] = move.Captured
+		b.setSquare(move.To, nil)
+		b.setSquare(Position{capturedPawnRow, move.To.Col}, move.Captured)
 	}
 
 	// Restore king position if necessary
@@ -402,109 +558,108 @@ func (b *Board) undoMove(move Move) {
 		}
 	}
 
+	if move.prevLastMove != nil {
+		b.lastMove = *move.prevLastMove
+	}
+	b.halfMoveClock = move.HalfMoveClock
+	b.castling = move.Castling
 	b.moveCount--
 }
 
+// IsInCheck reports whether any opponent piece could capture player's king,
+// looked up in the attack tables
 func (b *Board) IsInCheck(player Player) bool {
 	kingPos := b.whiteKing
 	if player == Black {
 		kingPos = b.blackKing
 	}
+	return b.isSquareAttacked(kingPos, 1-player)
+}
+
+// CheckingPieces returns the squares of the opponent's pieces giving check
+// to player's king: none, one, or two for a double check
+func (b *Board) CheckingPieces(player Player) []Position {
+	kingPos := b.whiteKing
+	if player == Black {
+		kingPos = b.blackKing
+	}
 
-	// Check if any opponent's piece can capture the king
+	var checkers []Position
 	for row := 0; row < 8; row++ {
 		for col := 0; col < 8; col++ {
 			piece := b.squares[row][col]
 			if piece != nil && piece.Player != player {
-				move, err := b.ValidateMove(Position{row, col}, kingPos, piece.Player)
-				if err == nil && b.IsValidPieceMove(piece, Position{row, col}, kingPos, &move) {
-					return true
+				if _, err := b.ValidateMove(Position{row, col}, kingPos, piece.Player); err == nil {
+					checkers = append(checkers, Position{row, col})
 				}
 			}
 		}
 	}
-	return false
+	return checkers
 }
 
+// IsCheckmate reports whether player is in check with no legal move. A
+// bare king counts like any other army: K+Q vs K with the lone king
+// attacked and every flight square covered is checkmate.
 func (b *Board) IsCheckmate(player Player) bool {
-	if !b.IsInCheck(player) {
-		return false
-	}
-
-	// Try all possible moves for all pieces
-	for row := 0; row < 8; row++ {
-		for col := 0; col < 8; col++ {
-			piece := b.squares[row][col]
-			if piece != nil && piece.Player == player {
-				for newRow := 0; newRow < 8; newRow++ {
-					for newCol := 0; newCol < 8; newCol++ {
-						oldPos := Position{row, col}
-						newPos := Position{newRow, newCol}
-
-						move, err := b.ValidateMove(oldPos, newPos, player)
-						if err != nil {
-							continue
-						}
-
-						b.makeMove(move)
-						stillInCheck := b.IsInCheck(player)
-						b.undoMove(move)
-
-						if !stillInCheck {
-							return false
-						}
-					}
-				}
-			}
-		}
-	}
-	return true
+	return b.IsInCheck(player) && !b.hasLegalMove(player)
 }
 
+// IsStalemate reports whether player is not in check but has no legal
+// move, such as a lone king boxed in by a pawn and king (k7/P7/1K6 with
+// Black to move) or by queen and king (k7/8/1QK5). Blocked or pinned
+// pieces don't count as legal moves, and every king step is tried on the
+// board, so squares next to the enemy king count as attacked.
 func (b *Board) IsStalemate(player Player) bool {
-	if b.IsInCheck(player) {
-		return false
-	}
+	return !b.IsInCheck(player) && !b.hasLegalMove(player)
+}
 
-	// Check if the player has any legal moves
-	for row := 0; row < 8; row++ {
-		for col := 0; col < 8; col++ {
-			piece := b.squares[row][col]
-			if piece != nil && piece.Player == player {
-				for newRow := 0; newRow < 8; newRow++ {
-					for newCol := 0; newCol < 8; newCol++ {
-						oldPos := Position{row, col}
-						newPos := Position{newRow, newCol}
-
-						move, err := b.ValidateMove(oldPos, newPos, player)
-						if err != nil {
-							continue
-						}
-
-						// Try the move
-						b.makeMove(move)
-						inCheck := b.IsInCheck(player)
-						b.undoMove(move)
-
-						if !inCheck {
-							return false
-						}
-					}
-				}
-			}
-		}
-	}
-	return true
+// hasLegalMove reports whether player has any move that doesn't leave
+// their king in check
+func (b *Board) hasLegalMove(player Player) bool {
+	return len(b.LegalMoves(player)) > 0
 }
 
 func isValidPosition(pos Position) bool {
 	return pos.Row >= 0 && pos.Row < 8 && pos.Col >= 0 && pos.Col < 8
 }
 
-func ParseMove(notation string) (Position, Position, error) {
-	notation = strings.ToLower(strings.TrimSpace(notation))
+// ParseMove parses a move in coordinate notation and returns its squares
+// and the comment in braces after it, if any; see ParseCoordinateMove to
+// keep a promotion choice
+func ParseMove(notation string) (Position, Position, string, error) {
+	move, err := ParseCoordinateMove(notation)
+	return move.From, move.To, move.Comment, err
+}
+
+// ParseCoordinateMove parses a move such as "e2-e4", with an optional
+// promotion piece letter as in "e7-e8n" and an optional comment as in
+// "e2-e4 {good central control}", into a Move holding only the squares,
+// the promotion choice and the comment. Squares are absolute algebraic
+// coordinates and never depend on how the board is drawn; see
+// RenderOptions.Flipped.
+func ParseCoordinateMove(notation string) (Move, error) {
+	notation, comment := SplitComment(notation)
+	notation = strings.ToLower(notation)
+	var promotion PieceType
+	if len(notation) == 6 && notation[2] == '-' {
+		pt, ok := pieceTypeFromLetter(notation[5])
+		if !ok || pt == Pawn || pt == King {
+			return Move{}, fmt.Errorf("invalid promotion piece %q (q, r, b or n)", notation[5:])
+		}
+		promotion = pt
+		notation = notation[:5]
+	}
 	if len(notation) != 5 || notation[2] != '-' {
-		return Position{}, Position{}, fmt.Errorf("invalid move format (example: e2-e4)")
+		return Move{}, fmt.Errorf("invalid move format (example: e2-e4)")
+	}
+
+	// Check each character explicitly: bytes outside a-h and 1-8 would
+	// otherwise turn into arbitrary, possibly on-board, rows and columns
+	for _, i := range []int{0, 3} {
+		if notation[i] < 'a' || notation[i] > 'h' || notation[i+1] < '1' || notation[i+1] > '8' {
+			return Move{}, fmt.Errorf("invalid square %q in move (files a-h, ranks 1-8; example: e2-e4)", notation[i:i+2])
+		}
 	}
 
 	fromCol := int(notation[0] - 'a')
@@ -512,11 +667,16 @@ func ParseMove(notation string) (Position, Position, error) {
 	toCol := int(notation[3] - 'a')
 	toRow := 8 - int(notation[4]-'0')
 
-	if !isValidPosition(Position{fromRow, fromCol}) || !isValidPosition(Position{toRow, toCol}) {
-		return Position{}, Position{}, fmt.Errorf("invalid position")
-	}
+	return Move{From: Position{fromRow, fromCol}, To: Position{toRow, toCol}, Promotion: promotion, Comment: comment}, nil
+}
 
-	return Position{fromRow, fromCol}, Position{toRow, toCol}, nil
+// ParseSquare parses a square in algebraic notation such as "e4"
+func ParseSquare(square string) (Position, error) {
+	square = strings.ToLower(strings.TrimSpace(square))
+	if len(square) != 2 || square[0] < 'a' || square[0] > 'h' || square[1] < '1' || square[1] > '8' {
+		return Position{}, fmt.Errorf("invalid square %q (example: e4)", square)
+	}
+	return Position{8 - int(square[1]-'0'), int(square[0] - 'a')}, nil
 }
 
 func ClearScreen() {
@@ -553,95 +713,374 @@ func sign(x int) int {
 	return 0
 }
 
+var (
+	protocolFlag        = flag.String("protocol", "", "run as a backend speaking the given protocol on stdin/stdout: json, or uci to act as an engine for chess GUIs")
+	pieceValuesFlag     = flag.String("piece-values", "", "override engine piece values in centipawns, e.g. q=500,n=300 (heuristics only, not legality)")
+	styleFlag           = flag.String("style", "balanced", "engine personality: balanced, aggressive, defensive or positional")
+	evalWeightsFlag     = flag.String("eval-weights", "", "override evaluation weights of the style in centipawns, e.g. open=30,kingattack=20 (open, halfopen, seventh, kingattack, kingsafety, loose, passed, bishoppair, knightbishop, rookminors)")
+	noClearFlag         = flag.Bool("no-clear", false, "don't clear the screen between moves and log a position summary instead")
+	evalBarFlag         = flag.Bool("evalbar", false, "show an evaluation bar under the board")
+	whiteFlag           = flag.String("white", "human", "move source for White: human, ai, random, script:PATH, fifo:PATH, connect:ADDR or listen:ADDR")
+	blackFlag           = flag.String("black", "human", "move source for Black: human, ai, random, script:PATH, fifo:PATH, connect:ADDR or listen:ADDR")
+	depthFlag           = flag.Int("depth", 2, "search depth in plies for the ai move source")
+	aiResignFlag        = flag.Bool("ai-resign", false, "let the ai resign hopeless positions instead of playing to the end")
+	borderFlag          = flag.String("border", "unicode", "board frame style: unicode, ascii or none")
+	gamesFlag           = flag.Int("games", 1, "number of games to start with; switch between them with 'switch <id>'")
+	dbFlag              = flag.String("db", "", "log the game to this file after every move so it can be resumed")
+	resumeFlag          = flag.String("resume", "", "resume the game logged in this file, and keep logging to it")
+	guidesFlag          = flag.Bool("guides", false, "draw gridlines between squares and file letters on every rank")
+	announceFlag        = flag.String("announce", "", "shell command receiving each move in SAN on stdin, one per line")
+	aliasFlag           = flag.String("alias", "", "input aliases added to the defaults, e.g. k=e1-g1,q=quit ({rank} is the mover's back rank)")
+	promptFlag          = flag.String("prompt", "{player} to move (example: e2-e4): ", "move prompt; {player} is replaced by the side to move")
+	hotseatFlipFlag     = flag.Bool("hotseat-flip", false, "two humans on one screen: show the board from the side to move")
+	hotseatPauseFlag    = flag.Bool("hotseat-pause", false, "with -hotseat-flip, hide the board and wait for Enter between turns")
+	warnBlundersFlag    = flag.Bool("warn-blunders", false, "ask for confirmation before a move that hangs the moved piece")
+	trainerFlag         = flag.Bool("trainer", false, "practice naming squares instead of playing a game")
+	verboseFlag         = flag.Bool("verbose", false, "explain in detail why a move is illegal")
+	serveFlag           = flag.String("serve", "", "serve a read-only live view of the game over HTTP on this address, e.g. :8080")
+	analyzeFENFlag      = flag.String("analyze-fen", "", "print whether the side to move in this FEN is in check, mated or stalemated, list its legal moves and exit")
+	autosaveFlag        = flag.String("autosave", "", "save the games in PGN to this file as they are played and on exit, including quit, end of input and Ctrl-C")
+	coachFlag           = flag.Bool("coach", false, "print a short teaching note the first time each piece type is captured")
+	historyFlag         = flag.Int("history", 0, "show only the last N moves of the history (0 shows all); the full game is kept for PGN")
+	evaluatorFlag       = flag.String("evaluator", "default", "evaluation function used by the engine: default or material, or one registered with RegisterEvaluator")
+	endgameFlag         = flag.String("endgame", "", "practice an endgame against the ai: KQvK, KRvK or KPvK")
+	endgameRandomFlag   = flag.Bool("endgame-random", false, "with -endgame, place the pieces on random squares")
+	seedFlag            = flag.Int64("seed", 0, "seed for random choices such as engine tie-breaks and the random player; the same seed replays the same game (0 picks one)")
+	showCapturesFlag    = flag.Bool("show-captures", false, "mark captures in the move history with the captured piece, e.g. e4xd5 (♟)")
+	selectFlag          = flag.Bool("select", false, "two-step move input: type a piece's square to highlight its legal moves, then the destination")
+	verifyFlag          = flag.String("verify", "", "replay the games in this PGN file, check the generated SAN matches every move and exit")
+	disableRulesFlag    = flag.String("disable-rules", "", "comma-separated rules to switch off for casual play: castling, en-passant, auto-queen (ask for the promotion piece), fifty-move, threefold")
+	warnThreatsFlag     = flag.Bool("warn-threats", false, "before each move, list your pieces that are attacked and not defended well enough; 'threats' toggles it in game")
+	colorFlag           = flag.Bool("color", false, "use terminal colors, e.g. to mark the last move in replay")
+	doubleCheckPlusFlag = flag.Bool("double-check-plus", false, "write a double check as ++ in displayed SAN; PGN export always uses +")
+	autosaveEveryFlag   = flag.Int("autosave-every", 1, "with -autosave, also save after every N moves so a crash loses at most N-1 (0 saves only on exit)")
+	fsyncFlag           = flag.Bool("fsync", false, "with -db, wait for each move to reach the disk, so even a power loss loses no moves")
+	drawHintFlag        = flag.Int("draw-hint", 30, "after this many plies without a capture or pawn move, suggest offering a draw (0 disables); only shown in games with a human player")
+	labelsFlag          = flag.Bool("labels", false, "name every empty square on the board, e.g. e4, drawing the board wider (dim with -color)")
+	showTurnFlag        = flag.Bool("show-turn", false, "mark the side to move beside the board edge nearest to it")
+	exportSVGFlag       = flag.String("export-svg", "", "on exit, write the current game to this directory as one SVG image per position and an index.html animating them")
+	focusFlag           = flag.Bool("focus", false, "with -select, dim every square but the selected piece and its legal destinations; without -color, list the destinations instead")
+	puzzlesFlag         = flag.Bool("puzzles", false, "practice tactics puzzles instead of playing a game")
+	movesFIFOFlag       = flag.String("moves-fifo", "", "read one side's moves from this named pipe as another program writes them, like fifo:PATH as its move source")
+	movesFIFOSideFlag   = flag.String("moves-fifo-side", "black", "with -moves-fifo, the side whose moves come from the pipe: white or black")
+)
+
 func main() {
-	board := NewBoard()
-	currentPlayer := White
-	scanner := bufio.NewScanner(os.Stdin)
-	moveHistory := make([]string, 0)
-
-	for {
-		ClearScreen()
-
-		// Display move history
-		fmt.Println("\nMove History:")
-		for i, move := range moveHistory {
-			if i%2 == 0 {
-				fmt.Printf("%d. %s", (i/2)+1, move)
-			} else {
-				fmt.Printf(" %s\n", move)
-			}
+	flag.Parse()
+
+	if *pieceValuesFlag != "" {
+		overrides, err := ParsePieceValues(*pieceValuesFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
 		}
-		fmt.Println("\n")
+		SetPieceValues(overrides)
+	}
+	style, ok := evalStyles[*styleFlag]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown style %q\n", *styleFlag)
+		os.Exit(2)
+	}
+	weights, err := ParseEvalWeights(*evalWeightsFlag, style)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	evalWeights = weights
+	eval, ok := evaluators[*evaluatorFlag]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown evaluator %q (available: %s)\n", *evaluatorFlag, strings.Join(evaluatorNames(), ", "))
+		os.Exit(2)
+	}
+	evaluator = eval
 
-		// Display the board
-		board.Draw()
+	rules, err := ParseRules(*disableRulesFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	gameRules = rules
 
-		// Check for checkmate or stalemate
-		if board.IsCheckmate(currentPlayer) {
-			winner := Black
-			if currentPlayer == Black {
-				winner = White
-			}
-			fmt.Printf("\nCheckmate! %s wins!\n", winner)
-			break
+	border, ok := borderStyles[*borderFlag]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown border style %q\n", *borderFlag)
+		os.Exit(2)
+	}
+	renderOptions.Border = border
+	renderOptions.Guides = *guidesFlag
+	renderOptions.Color = *colorFlag
+	renderOptions.Labels = *labelsFlag
+	renderOptions.ShowTurn = *showTurnFlag
+
+	aliases, err := ParseAliases(*aliasFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	inputAliases = aliases
+
+	switch *protocolFlag {
+	case "":
+	case "json":
+		if err := RunJSONProtocol(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case "uci":
+		if err := RunUCI(os.Stdin, os.Stdout, *depthFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown protocol %q\n", *protocolFlag)
+		os.Exit(2)
+	}
 
-		if board.IsStalemate(currentPlayer) {
-			fmt.Println("\nStalemate! The game is a draw.")
-			break
+	if *analyzeFENFlag != "" {
+		if err := AnalyzeFEN(*analyzeFENFlag, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		return
+	}
 
-		// Show if the current player is in check
-		if board.IsInCheck(currentPlayer) {
-			fmt.Printf("\n%s is in check!\n", currentPlayer)
+	if *verifyFlag != "" {
+		if err := VerifyPGN(*verifyFlag, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			var replayErr *ReplayError
+			if errors.As(err, &replayErr) {
+				fmt.Fprintf(os.Stderr, "\nThe game stops at this position:\n%s", replayErr.Board.DrawString(renderOptions))
+			}
+			os.Exit(1)
 		}
+		return
+	}
+
+	seed := *seedFlag
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	seededRand = rand.New(rand.NewSource(seed))
 
-		// Prompt for move
-		fmt.Printf("\n%s to move (example: e2-e4): ", currentPlayer)
-		if !scanner.Scan() {
-			break
+	scanner := bufio.NewScanner(os.Stdin)
+	if *trainerFlag {
+		RunTrainer(scanner, seededRand)
+		return
+	}
+	if *puzzlesFlag {
+		puzzles, err := ParsePuzzles(embeddedPuzzles)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		moveStr := scanner.Text()
+		RunPuzzles(puzzles, scanner)
+		return
+	}
 
-		// Handle special commands
-		switch moveStr {
-		case "quit":
-			fmt.Println("Game ended.")
+	// Without flags, let the player pick the game from a menu
+	startFEN := ""
+	if showMenu() {
+		choice, ok := RunMenu(scanner)
+		if !ok {
 			return
-		case "help":
-			fmt.Println("\nCommands:")
-			fmt.Println("- Enter moves in the format: e2-e4")
-			fmt.Println("- 'quit' to end the game")
-			fmt.Println("- 'help' to show this help message")
-			fmt.Println("\nPress Enter to continue...")
-			scanner.Scan()
-			continue
-		}
-
-		// Parse and make the move
-		oldPos, newPos, err := ParseMove(moveStr)
+		}
+		*whiteFlag, *blackFlag, *depthFlag = choice.White, choice.Black, choice.Depth
+		*resumeFlag, startFEN = choice.Resume, choice.FEN
+	}
+
+	games := NewGameManager(*gamesFlag)
+	if *resumeFlag != "" {
+		g, err := LoadGameLog(*resumeFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			var replayErr *ReplayError
+			if errors.As(err, &replayErr) {
+				fmt.Fprintf(os.Stderr, "\nThe game stops at this position:\n%s", replayErr.Board.DrawString(renderOptions))
+			}
+			os.Exit(1)
+		}
+		games.Replace(g)
+		if *dbFlag == "" {
+			*dbFlag = *resumeFlag
+		}
+	}
+	if *endgameFlag != "" {
+		var rng *rand.Rand
+		if *endgameRandomFlag {
+			rng = seededRand
+		}
+		fen, err := EndgamePosition(*endgameFlag, rng)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			fmt.Println("Press Enter to continue...")
-			scanner.Scan()
-			continue
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
 		}
+		g, err := NewGameFromFEN(fen)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		games.Replace(g)
+
+		// The engine defends unless Black was chosen explicitly
+		blackSet := false
+		flag.Visit(func(f *flag.Flag) { blackSet = blackSet || f.Name == "black" })
+		if !blackSet {
+			*blackFlag = "ai"
+		}
+	}
+	if startFEN != "" {
+		g, err := NewGameFromFEN(startFEN)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		games.Replace(g)
+	}
+	// Ctrl-C and SIGTERM stop the games rather than the process, so that
+	// the logs, saves and exports deferred below still happen. The exit
+	// status still tells a script the game was interrupted.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	games.Interrupts = interrupts
+	defer func() {
+		if games.Interrupted {
+			os.Exit(130)
+		}
+	}()
+
+	var gameLog *GameLog
+	if *dbFlag != "" {
+		log, err := CreateGameLog(*dbFlag, games.Current())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		log.Sync = *fsyncFlag
+		defer log.Close()
+		games.AddListener(log)
+		gameLog = log
+	}
+	if *autosaveFlag != "" {
+		autosave := NewAutosave(games, *autosaveFlag, *autosaveEveryFlag)
+		games.AddListener(autosave)
+		defer autosave.save()
+	}
+	if *exportSVGFlag != "" {
+		defer func() {
+			if _, err := games.Current().ExportSVG(*exportSVGFlag); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not export SVG: %v\n", err)
+			}
+		}()
+	}
+	if *serveFlag != "" {
+		server, err := Serve(*serveFlag, games.Current())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		games.AddListener(server)
+	}
+	if *coachFlag {
+		games.AddListener(&Coach{games: games})
+	}
+	if *announceFlag != "" {
+		announcer, err := StartAnnouncer(*announceFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not start announce command: %v\n", err)
+		} else {
+			defer announcer.Close()
+			games.AddListener(announcer)
+		}
+	}
+
+	if *movesFIFOFlag != "" {
+		side, ok := map[string]*string{"white": whiteFlag, "black": blackFlag}[*movesFIFOSideFlag]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown side %q for -moves-fifo-side\n", *movesFIFOSideFlag)
+			os.Exit(2)
+		}
+		sideSet := false
+		flag.Visit(func(f *flag.Flag) { sideSet = sideSet || f.Name == *movesFIFOSideFlag })
+		if sideSet {
+			fmt.Fprintf(os.Stderr, "Error: -moves-fifo plays %s, which -%s sets too\n", *movesFIFOSideFlag, *movesFIFOSideFlag)
+			os.Exit(2)
+		}
+		*side = "fifo:" + *movesFIFOFlag
+	}
 
-		err = board.Move(oldPos, newPos, currentPlayer)
+	var sources [2]MoveSource
+	for player, spec := range [2]string{*whiteFlag, *blackFlag} {
+		source, err := NewMoveSource(spec, Player(player), scanner, *depthFlag, games)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			fmt.Println("Press Enter to continue...")
-			scanner.Scan()
-			continue
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
 		}
+		sources[player] = source
+	}
+
+	_, whiteHuman := sources[White].(*HumanSource)
+	_, blackHuman := sources[Black].(*HumanSource)
+	if *hotseatFlipFlag {
+		if !whiteHuman || !blackHuman {
+			fmt.Fprintf(os.Stderr, "Error: -hotseat-flip needs two human players\n")
+			os.Exit(2)
+		}
+		if *hotseatPauseFlag {
+			games.AddListener(&HotseatPause{scanner: scanner})
+		}
+	}
 
-		// Record the move
-		moveHistory = append(moveHistory, moveStr)
+	// The draw hint is for people; engines and scripts don't need it
+	if *drawHintFlag > 0 && (whiteHuman || blackHuman) {
+		games.AddListener(&DrawHint{games: games, After: *drawHintFlag})
+	}
 
-		// Switch player
-		currentPlayer = 1 - currentPlayer
+	games.Run(sources)
+
+	// Offer people a rematch of a finished game, keeping score across games.
+	// Each rematch starts from the same position with fresh move sources,
+	// so the engine doesn't carry over its count of losing moves, but a
+	// network peer keeps its connection and its colour.
+	match := NewMatch(games.Current(), [2]string{*whiteFlag, *blackFlag}, sources)
+	for (whiteHuman || blackHuman) && games.Current().Outcome() != "" {
+		match.Record(games.Current())
+		fmt.Printf("\n%s\n", match.Score())
+		fmt.Print("\nType 'rematch' to play again, or press Enter to exit: ")
+		var rematch bool
+		if !games.Wait(func() { rematch = scanner.Scan() && strings.ToLower(strings.TrimSpace(scanner.Text())) == "rematch" }) || !rematch {
+			return
+		}
+		for player, spec := range match.Rematch() {
+			if _, ok := sources[player].(*NetworkSource); ok {
+				continue
+			}
+			source, err := NewMoveSource(spec, Player(player), scanner, *depthFlag, games)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			sources[player] = source
+		}
+		g, err := NewGameFromFEN(match.Start)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		games.New()
+		games.Replace(g)
+		if gameLog != nil {
+			if err := gameLog.Follow(g); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not write game log: %v\n", err)
+			}
+		}
+		games.Run(sources)
 	}
 
+	if games.Interrupted {
+		return
+	}
 	fmt.Println("\nPress Enter to exit...")
-	scanner.Scan()
+	games.Wait(func() { scanner.Scan() })
 }
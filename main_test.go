@@ -0,0 +1,387 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"testing"
+)
+
+// boardState captures everything makeMove changes, so that a test can
+// check undoMove puts all of it back
+type boardState struct {
+	fen            string
+	squares        [8][8]*Piece
+	hasMoved       [8][8]bool
+	lastMove       string
+	castling       CastlingRights
+	whiteKing      Position
+	blackKing      Position
+	halfMoveClock  int
+	moveCount      int
+	bb             bitboards
+	positionCounts map[string]int
+}
+
+func stateOf(b *Board) boardState {
+	s := boardState{
+		fen:            b.ToFEN(),
+		squares:        b.squares,
+		lastMove:       fmt.Sprint(b.lastMove.From, b.lastMove.To, b.lastMove.Piece),
+		castling:       b.castling,
+		whiteKing:      b.whiteKing,
+		blackKing:      b.blackKing,
+		halfMoveClock:  b.halfMoveClock,
+		moveCount:      b.moveCount,
+		bb:             b.bb,
+		positionCounts: maps.Clone(b.positionCounts),
+	}
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			if piece := b.squares[row][col]; piece != nil {
+				s.hasMoved[row][col] = piece.HasMoved
+			}
+		}
+	}
+	return s
+}
+
+// checkState reports the first difference between two board states
+func checkState(t *testing.T, context string, got, want boardState) {
+	t.Helper()
+	switch {
+	case got.fen != want.fen:
+		t.Errorf("%s: FEN %s, want %s", context, got.fen, want.fen)
+	case got.squares != want.squares:
+		t.Errorf("%s: pieces were replaced", context)
+	case got.hasMoved != want.hasMoved:
+		t.Errorf("%s: HasMoved flags differ", context)
+	case got.lastMove != want.lastMove:
+		t.Errorf("%s: last move %s, want %s", context, got.lastMove, want.lastMove)
+	case got.castling != want.castling:
+		t.Errorf("%s: castling rights %v, want %v", context, got.castling, want.castling)
+	case got.whiteKing != want.whiteKing || got.blackKing != want.blackKing:
+		t.Errorf("%s: kings on %s and %s, want %s and %s", context, got.whiteKing, got.blackKing, want.whiteKing, want.blackKing)
+	case got.halfMoveClock != want.halfMoveClock || got.moveCount != want.moveCount:
+		t.Errorf("%s: move counters differ", context)
+	case got.bb != want.bb:
+		t.Errorf("%s: bitboards differ", context)
+	case !maps.Equal(got.positionCounts, want.positionCounts):
+		t.Errorf("%s: repetition counts differ", context)
+	}
+}
+
+// playMove makes a move given in coordinate notation, checking it is legal
+func playMove(t *testing.T, b *Board, notation string) Move {
+	t.Helper()
+	parsed, err := ParseCoordinateMove(notation)
+	if err != nil {
+		t.Fatalf("%s: %v", notation, err)
+	}
+	move, err := b.ResolveMove(parsed, b.ToMove())
+	if err != nil {
+		t.Fatalf("%s: %s: %v", b.ToFEN(), notation, err)
+	}
+	b.makeMove(move)
+	return move
+}
+
+func TestUndoCastling(t *testing.T) {
+	tests := []struct {
+		fen, move, after string
+	}{
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10", "e1-g1", "r3k2r/8/8/8/8/8/8/R4RK1 b kq - 4 10"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10", "e1-c1", "r3k2r/8/8/8/8/8/8/2KR3R b kq - 4 10"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 3 10", "e8-g8", "r4rk1/8/8/8/8/8/8/R3K2R w KQ - 4 11"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 3 10", "e8-c8", "2kr3r/8/8/8/8/8/8/R3K2R w KQ - 4 11"},
+		// Moving or losing a rook only gives up castling on its side
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10", "h1-h2", "r3k2r/8/8/8/8/8/7R/R3K3 b Qkq - 4 10"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10", "a1-a8", "R3k2r/8/8/8/8/8/8/4K2R b Kk - 0 10"},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		before := stateOf(board)
+		move := playMove(t, board, tt.move)
+		if got := board.ToFEN(); got != tt.after {
+			t.Errorf("%s after %s: %s, want %s", tt.fen, tt.move, got, tt.after)
+		}
+		board.undoMove(move)
+		checkState(t, tt.fen+" undo "+tt.move, stateOf(board), before)
+
+		// The rook is unmoved again, so castling is still possible
+		if _, err := board.CheckMove(move.From, move.To, move.Piece.Player); err != nil {
+			t.Errorf("%s: %s illegal after undo: %v", tt.fen, tt.move, err)
+		}
+	}
+}
+
+func TestCastlingSquares(t *testing.T) {
+	// The king may not castle out of, through or into check. Queenside the
+	// b-file square must be empty too, but it may be attacked.
+	checkLegality(t, []legalityTest{
+		{"4k3/8/8/8/8/8/8/R3K3 w Q - 0 1", "e1-c1", true},
+		{"1r2k3/8/8/8/8/8/8/R3K3 w Q - 0 1", "e1-c1", true},  // b1 attacked
+		{"4k3/8/8/8/8/8/8/RN2K3 w Q - 0 1", "e1-c1", false},  // b1 occupied
+		{"2r1k3/8/8/8/8/8/8/R3K3 w Q - 0 1", "e1-c1", false}, // c1 attacked
+		{"3rk3/8/8/8/8/8/8/R3K3 w Q - 0 1", "e1-c1", false},  // d1 attacked
+		{"4k3/4r3/8/8/8/8/8/R3K3 w Q - 0 1", "e1-c1", false}, // in check
+		{"r3k3/8/8/8/8/8/8/1R2K3 b q - 0 1", "e8-c8", true},  // b8 attacked
+		{"r3k3/8/8/8/8/8/8/3RK3 b q - 0 1", "e8-c8", false},  // d8 attacked
+		{"rn2k3/8/8/8/8/8/8/4K3 b q - 0 1", "e8-c8", false},  // b8 occupied
+		{"4k2r/8/8/8/8/8/8/4K2R w K - 0 1", "e1-g1", true},   // h1 attacked
+		{"4kr2/8/8/8/8/8/8/4K2R w K - 0 1", "e1-g1", false},  // f1 attacked
+		{"4k1r1/8/8/8/8/8/8/4K2R w K - 0 1", "e1-g1", false}, // g1 attacked
+	})
+}
+
+func TestUndoEnPassant(t *testing.T) {
+	board, err := BoardFromFEN("4k3/3p4/8/4P3/8/8/8/4K3 b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := stateOf(board)
+	advance := playMove(t, board, "d7-d5")
+	beforeCapture := stateOf(board)
+	if got := board.ToFEN(); got != "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2" {
+		t.Errorf("after d7-d5: %s", got)
+	}
+
+	capture := playMove(t, board, "e5-d6")
+	if !capture.IsEnPassant || capture.Captured == nil || capture.Captured.Type != Pawn {
+		t.Fatalf("e5-d6 is not an en passant capture: %+v", capture)
+	}
+	if got := board.ToFEN(); got != "4k3/8/3P4/8/8/8/8/4K3 b - - 0 2" {
+		t.Errorf("after e5-d6: %s", got)
+	}
+
+	board.undoMove(capture)
+	checkState(t, "undo e5-d6", stateOf(board), beforeCapture)
+	board.undoMove(advance)
+	checkState(t, "undo d7-d5", stateOf(board), start)
+}
+
+func TestEnPassantExpires(t *testing.T) {
+	// The capture is only possible on the ply right after the advance
+	board, err := BoardFromFEN("4k3/3p4/8/4P3/8/8/8/4K3 b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	playMove(t, board, "d7-d5")
+	playMove(t, board, "e1-d1")
+	playMove(t, board, "e8-e7")
+	if _, err := board.CheckMove(Position{3, 4}, Position{2, 3}, White); err == nil {
+		t.Errorf("en passant still allowed two plies later in %s", board.ToFEN())
+	}
+}
+
+func TestIsInCheck(t *testing.T) {
+	tests := []struct {
+		fen  string
+		want bool
+	}{
+		{"4k3/8/8/b7/8/8/8/4K3 w - - 0 1", true},                                  // Bishop along the diagonal
+		{"4k3/8/8/b7/8/8/3P4/4K3 w - - 0 1", false},                               // blocked by a pawn on d2
+		{"4k3/8/8/b7/8/2n5/8/4K3 w - - 0 1", false},                               // or by its own knight on c3
+		{"4k3/8/8/8/8/8/8/r3K3 w - - 0 1", true},                                  // Rook along the rank
+		{"4k3/8/8/8/8/8/8/rN2K3 w - - 0 1", false},                                // blocked on b1
+		{"4k3/8/8/8/8/8/8/r1n1K3 w - - 0 1", false},                               // or by a knight that doesn't check
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", true},   // Queen on h4 after f3 and g4
+		{"rnb1kbnr/pppp1ppp/8/4p3/7q/5PP1/PPPPP2P/RNBQKBNR w KQkq - 1 3", false},  // g3 closes the diagonal
+		{"rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2", false}, // Nothing reaches e8 through the pawns
+		{"4k3/8/8/8/8/3n4/8/4K3 w - - 0 1", true},                                 // Knight
+		{"4k3/8/8/8/8/2PnP3/2PPP3/4K3 w - - 0 1", true},                           // jumps over a wall of pawns
+		{"4k3/8/8/8/8/8/3p4/4K3 w - - 0 1", true},                                 // Black pawn diagonally in front
+		{"4k3/8/8/8/8/8/4p3/4K3 w - - 0 1", false},                                // but not straight ahead
+		{"4k3/3P4/8/8/8/8/8/4K3 b - - 0 1", true},                                 // White pawn
+		{"4k3/4P3/8/8/8/8/8/4K3 b - - 0 1", false},                                // nor straight ahead
+		{"4k3/8/8/8/8/8/8/4RK2 b - - 0 1", true},                                  // Rook up the file
+		{"4k3/8/8/8/4N3/8/8/4RK2 b - - 0 1", false},                               // blocked by its own knight
+		{"4k3/4Q3/8/8/8/8/8/4K3 b - - 0 1", true},                                 // Queen next to the king
+		{"3rkr2/3p1p2/8/7B/B7/8/8/4K3 b - - 0 1", false},                          // Bishops on both diagonals, behind the pawns
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		player := board.ToMove()
+		if got := board.IsInCheck(player); got != tt.want {
+			t.Errorf("%s: IsInCheck(%s) = %v, want %v", tt.fen, player, got, tt.want)
+		}
+		if got := board.isInCheckByScan(player); got != tt.want {
+			t.Errorf("%s: isInCheckByScan(%s) = %v, want %v", tt.fen, player, got, tt.want)
+		}
+	}
+}
+
+// legalityTest is a move in coordinate notation and whether the side to
+// move may play it in the position
+type legalityTest struct {
+	fen, move string
+	legal     bool
+}
+
+// checkLegality checks each move against CheckMove and LegalMoves, which
+// must agree
+func checkLegality(t *testing.T, tests []legalityTest) {
+	t.Helper()
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		parsed, err := ParseCoordinateMove(tt.move)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.move, err)
+		}
+		_, err = board.CheckMove(parsed.From, parsed.To, board.ToMove())
+		if legal := err == nil; legal != tt.legal {
+			t.Errorf("%s: %s legal = %v (%v), want %v", tt.fen, tt.move, legal, err, tt.legal)
+		}
+		generated := false
+		for _, move := range board.LegalMoves(board.ToMove()) {
+			generated = generated || move.From == parsed.From && move.To == parsed.To
+		}
+		if generated != tt.legal {
+			t.Errorf("%s: %s generated = %v, want %v", tt.fen, tt.move, generated, tt.legal)
+		}
+	}
+}
+
+func TestKingCaptures(t *testing.T) {
+	// The king may only take a piece nothing else defends
+	checkLegality(t, []legalityTest{
+		{"8/8/8/8/3pK3/8/8/7k w - - 0 1", "e4-d4", true},
+		{"8/8/8/2p5/3pK3/8/8/7k w - - 0 1", "e4-d4", false},  // Defended by a pawn
+		{"8/8/8/8/r2nK3/8/8/7k w - - 0 1", "e4-d4", false},   // by a rook behind it
+		{"8/8/8/8/3pK3/2k5/8/8 w - - 0 1", "e4-d4", false},   // by the king
+		{"8/8/8/3Pk3/8/8/B7/K7 b - - 0 1", "e5-d5", false},   // by a bishop
+		{"8/8/8/3Pk3/8/1P6/B7/K7 b - - 0 1", "e5-d5", true},  // but not from behind a pawn
+		{"8/8/8/8/r1PnK3/8/8/7k w - - 0 1", "e4-d4", true},   // nor a rook behind one
+		{"4k3/8/8/8/8/8/4q3/4K3 w - - 0 1", "e1-e2", true},   // A queen checking next to the king
+		{"4k3/8/8/8/8/8/4q3/3bK3 w - - 0 1", "e1-e2", false}, // defended from d1
+	})
+}
+
+func TestIsStalemate(t *testing.T) {
+	tests := []struct {
+		fen  string
+		want bool
+	}{
+		{"k7/P7/1K6/8/8/8/8/8 b - - 0 1", true},     // Pawn and king
+		{"k7/8/1QK5/8/8/8/8/8 b - - 0 1", true},     // Queen and king
+		{"k7/8/1Q6/8/8/8/8/7K b - - 0 1", true},     // Queen alone
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", true},    // Queen a knight's move away
+		{"5k2/5P2/5K2/8/8/8/8/8 b - - 0 1", true},   // King in front of its pawn
+		{"k7/8/1QK5/8/8/p7/P7/8 b - - 0 1", true},   // A blocked pawn can't move either
+		{"7k/8/6Q1/8/8/4K3/1r6/8 b - - 0 1", false}, // The rook can still move
+		{"k7/8/1QK5/8/8/8/p7/8 b - - 0 1", false},   // The pawn can promote
+		{"k7/1Q6/1K6/8/8/8/8/8 b - - 0 1", false},   // Checkmate
+		{"k7/8/1K6/8/8/8/8/2Q5 b - - 0 1", false},   // b8 is free
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		player := board.ToMove()
+		if got := board.IsStalemate(player); got != tt.want {
+			t.Errorf("%s: IsStalemate(%s) = %v, want %v", tt.fen, player, got, tt.want)
+		}
+		if tt.want && board.Result().Reason != Stalemate {
+			t.Errorf("%s: result %s, want stalemate", tt.fen, board.Result().Reason)
+		}
+	}
+}
+
+func TestKingStepsAlongCheckingRay(t *testing.T) {
+	// A king in check can't step away along the checking ray: the square
+	// it moves to was only shadowed by the king itself
+	checkLegality(t, []legalityTest{
+		{"4k3/8/8/8/8/8/8/r3K3 w - - 0 1", "e1-f1", false},
+		{"4k3/8/8/8/8/8/8/r3K3 w - - 0 1", "e1-d1", false}, // nor towards the rook
+		{"4k3/8/8/8/8/8/8/r3K3 w - - 0 1", "e1-f2", true},
+		{"4k3/8/8/8/4K3/8/8/1b6 w - - 0 1", "e4-f5", false},
+		{"4k3/8/8/8/4K3/8/8/1b6 w - - 0 1", "e4-d5", true},
+		{"4k3/8/8/4K3/8/8/8/4q3 w - - 0 1", "e5-e6", false},
+		{"4k3/8/8/4K3/8/8/8/4q3 w - - 0 1", "e5-d6", true},
+		{"4k2R/8/8/8/8/8/8/4K3 b - - 0 1", "e8-d8", false},
+		{"4k2R/8/8/8/8/8/8/4K3 b - - 0 1", "e8-d7", true},
+	})
+}
+
+func TestMoveToSameSquare(t *testing.T) {
+	// Without its own check, the piece would be reported as capturing itself
+	for _, notation := range []string{"e2-e2", "e1-e1", "g1-g1"} {
+		board := NewBoard()
+		parsed, err := ParseCoordinateMove(notation)
+		if err != nil {
+			t.Fatalf("%s: %v", notation, err)
+		}
+		_, err = board.ResolveMove(parsed, White)
+		if want := "a piece must move to a different square"; err == nil || err.Error() != want {
+			t.Errorf("%s: error %v, want %q", notation, err, want)
+		}
+	}
+}
+
+func TestParseMoveBadCharacters(t *testing.T) {
+	tests := []struct {
+		notation string
+		want     string // The error, or "" for a valid move
+	}{
+		{"e2-e4", ""},
+		{"E2-E4", ""},
+		{"i2-e4", `invalid square "i2" in move (files a-h, ranks 1-8; example: e2-e4)`},
+		{"`2-e4", "invalid square \"`2\" in move (files a-h, ranks 1-8; example: e2-e4)"},
+		{"e9-e4", `invalid square "e9" in move (files a-h, ranks 1-8; example: e2-e4)`},
+		{"e0-e4", `invalid square "e0" in move (files a-h, ranks 1-8; example: e2-e4)`},
+		{"e2-z4", `invalid square "z4" in move (files a-h, ranks 1-8; example: e2-e4)`},
+		{"e2-e:", `invalid square "e:" in move (files a-h, ranks 1-8; example: e2-e4)`},
+		{"e2-44", `invalid square "44" in move (files a-h, ranks 1-8; example: e2-e4)`},
+		{"e2xe4", "invalid move format (example: e2-e4)"},
+		{"e2-e", "invalid move format (example: e2-e4)"},
+		// Two bytes long, so without the dash in place it's no promotion
+		{"é2-e4", "invalid move format (example: e2-e4)"},
+		{"e7-e8k", `invalid promotion piece "k" (q, r, b or n)`},
+	}
+	for _, tt := range tests {
+		_, _, _, err := ParseMove(tt.notation)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("ParseMove(%q) error %q, want %q", tt.notation, got, tt.want)
+		}
+	}
+}
+
+func TestParseMoveComment(t *testing.T) {
+	tests := []struct{ notation, move, comment string }{
+		{"e2-e4", "e2-e4", ""},
+		{"e2-e4 {good central control}", "e2-e4", "good central control"},
+		{"e7-e8n{ forks king and queen }", "e7-e8n", "forks king and queen"},
+		{"E2-E4 {Open Game}", "e2-e4", "Open Game"},
+	}
+	for _, tt := range tests {
+		from, to, comment, err := ParseMove(tt.notation)
+		if err != nil {
+			t.Errorf("ParseMove(%q): %v", tt.notation, err)
+			continue
+		}
+		if got := from.String() + "-" + to.String(); got != tt.move[:5] || comment != tt.comment {
+			t.Errorf("ParseMove(%q) = %s, %q, want %s, %q", tt.notation, got, comment, tt.move[:5], tt.comment)
+		}
+		move, err := ParseCoordinateMove(tt.notation)
+		if err != nil {
+			t.Errorf("ParseCoordinateMove(%q): %v", tt.notation, err)
+			continue
+		}
+		if move.String() != tt.move || move.Comment != tt.comment {
+			t.Errorf("ParseCoordinateMove(%q) = %s, %q, want %s, %q", tt.notation, move, move.Comment, tt.move, tt.comment)
+		}
+	}
+}
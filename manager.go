@@ -0,0 +1,335 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrGameSwitched is returned by a MoveSource when the player switched to
+// another game instead of moving
+var ErrGameSwitched = errors.New("switched game")
+
+// ErrUndo is returned by a MoveSource when the player takes back a move
+var ErrUndo = errors.New("undo")
+
+// ErrResign is returned by a MoveSource when its side resigns
+var ErrResign = errors.New("resigned")
+
+// ErrDraw is returned by a MoveSource when its side claims or offers a draw
+var ErrDraw = errors.New("draw")
+
+// GameManager holds several independent games addressable by ID, one of
+// which is the current game being played
+type GameManager struct {
+	games     map[int]*Game
+	current   int
+	nextID    int
+	notice    string // Shown once on the next render
+	listeners []MoveListener
+
+	// Interrupts, if set, stops the games when a signal arrives, even
+	// while a source is waiting for input or searching, so that the
+	// program can save and close everything on its normal way out
+	Interrupts  <-chan os.Signal
+	Interrupted bool // A signal arrived
+}
+
+// NewGameManager creates a manager with n new games, the first one current
+func NewGameManager(n int) *GameManager {
+	m := &GameManager{games: make(map[int]*Game), nextID: 1}
+	for i := 0; i < max(n, 1); i++ {
+		m.New()
+	}
+	m.current = 1
+	return m
+}
+
+// New starts a new game, makes it current and returns it
+func (m *GameManager) New() *Game {
+	g := NewGame()
+	g.ID = m.nextID
+	m.nextID++
+	m.games[g.ID] = g
+	m.current = g.ID
+	return g
+}
+
+// Replace puts g in place of the current game, keeping its ID
+func (m *GameManager) Replace(g *Game) {
+	g.ID = m.current
+	m.games[m.current] = g
+}
+
+// AddListener registers a listener notified of every move played
+func (m *GameManager) AddListener(l MoveListener) {
+	m.listeners = append(m.listeners, l)
+}
+
+// Notify shows msg once, after the board is next drawn
+func (m *GameManager) Notify(msg string) {
+	if m.notice != "" {
+		msg = m.notice + "\n" + msg
+	}
+	m.notice = msg
+}
+
+// Switch makes the game with the given ID current
+func (m *GameManager) Switch(id int) error {
+	if _, ok := m.games[id]; !ok {
+		return fmt.Errorf("no game with ID %d", id)
+	}
+	m.current = id
+	return nil
+}
+
+// Current returns the game being played
+func (m *GameManager) Current() *Game {
+	return m.games[m.current]
+}
+
+// IDs returns the IDs of all games in ascending order
+func (m *GameManager) IDs() []int {
+	ids := make([]int, 0, len(m.games))
+	for id := range m.games {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// List prints every game with its status, marking the current one
+func (m *GameManager) List() {
+	for _, id := range m.IDs() {
+		g := m.games[id]
+		marker := " "
+		if id == m.current {
+			marker = "*"
+		}
+		status := g.Outcome()
+		if status == "" {
+			status = fmt.Sprintf("%s to move", g.Board.ToMove())
+		}
+		fmt.Printf("%s %d: %d moves, %s\n", marker, id, len(g.Moves), status)
+	}
+}
+
+// Wait runs f and returns true once it finishes, or false as soon as a
+// signal arrives on Interrupts. f is then left to finish on its own, so it
+// must not touch the games: a source blocked on input does nothing more,
+// and the engine searches a copy of the board.
+func (m *GameManager) Wait(f func()) bool {
+	if m.Interrupted {
+		return false
+	}
+	if m.Interrupts == nil {
+		f()
+		return true
+	}
+	done := make(chan struct{})
+	go func() {
+		f()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-m.Interrupts:
+		m.Interrupted = true
+		return false
+	}
+}
+
+// nextUnfinished returns the first game after the current one that is
+// still in progress, or nil if every game is over
+func (m *GameManager) nextUnfinished() *Game {
+	ids := m.IDs()
+	for i := range ids {
+		// Start after the current game and wrap around
+		id := ids[(sort.SearchInts(ids, m.current)+1+i)%len(ids)]
+		if m.games[id].Outcome() == "" {
+			return m.games[id]
+		}
+	}
+	return nil
+}
+
+// Run plays the games until all of them are over or a player quits, asking
+// sources[White] and sources[Black] for moves in the current game
+func (m *GameManager) Run(sources [2]MoveSource) {
+	for {
+		g := m.Current()
+		board := g.Board
+		currentPlayer := board.ToMove()
+
+		g.Render()
+		if len(m.games) > 1 {
+			fmt.Printf("\nGame %d of %d\n", g.ID, len(m.games))
+		}
+		if m.notice != "" {
+			fmt.Printf("\n%s\n", m.notice)
+			m.notice = ""
+		}
+
+		// Check for checkmate, stalemate and automatic draws
+		if outcome := g.Outcome(); outcome != "" {
+			next := m.nextUnfinished()
+			if next == nil {
+				fmt.Printf("\n%s\n", outcome)
+				m.printTimes(g, sources)
+				return
+			}
+			m.notice = fmt.Sprintf("Game %d ended: %s", g.ID, outcome)
+			m.current = next.ID
+			continue
+		}
+
+		// Show if the current player is in check, and where the king can go
+		if board.IsInCheck(currentPlayer) {
+			fmt.Printf("\n%s is in check!\n", currentPlayer)
+			if escapes := board.KingEscapes(currentPlayer); len(escapes) > 0 {
+				squares := make([]string, len(escapes))
+				for i, pos := range escapes {
+					squares[i] = pos.String()
+				}
+				fmt.Printf("King escape squares: %s\n", strings.Join(squares, ", "))
+			} else {
+				fmt.Println("The king has no escape squares: capture or block the checking piece.")
+			}
+		}
+
+		asked := time.Now()
+		var next struct {
+			move Move
+			err  error
+		}
+		if !m.Wait(func() { next.move, next.err = sources[currentPlayer].NextMove(g) }) {
+			fmt.Println("\nGame interrupted.")
+			m.printTimes(g, sources)
+			return
+		}
+		move, err := next.move, next.err
+		thinking := time.Since(asked)
+		if errors.Is(err, ErrGameSwitched) {
+			continue
+		}
+		if errors.Is(err, ErrUndo) {
+			if err := m.undo(g, sources); err != nil {
+				m.notice = fmt.Sprintf("Error: %v", err)
+			}
+			continue
+		}
+		// A draw claimed with a move is decided once the move is played
+		claim := errors.Is(err, ErrDraw) && move.Piece != nil
+		if errors.Is(err, ErrDraw) && !claim {
+			m.draw(g, sources)
+			continue
+		}
+		if errors.Is(err, ErrResign) {
+			g.Resign(currentPlayer)
+			continue
+		}
+		if errors.Is(err, ErrQuit) {
+			fmt.Println("Game ended.")
+			m.printTimes(g, sources)
+			return
+		}
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		// A source with nothing to play lets the result end the game, in
+		// case the position changed since the check above
+		if errors.Is(err, ErrNoLegalMoves) && g.Outcome() != "" {
+			continue
+		}
+		if err != nil && !claim {
+			fmt.Printf("\nError: %s could not move: %v\n", currentPlayer, err)
+			return
+		}
+
+		if err := g.Play(move); err != nil {
+			fmt.Printf("\nError: %s played an illegal move %s: %v\n", currentPlayer, move, err)
+			return
+		}
+
+		g.clocks[currentPlayer].total += thinking
+		g.clocks[currentPlayer].moves++
+
+		// Let sources that mirror the game elsewhere see the move
+		played := g.Moves[len(g.Moves)-1]
+		for i, source := range sources {
+			if i == 1 && source == sources[0] {
+				break
+			}
+			if listener, ok := source.(MoveListener); ok {
+				listener.MoveMade(g, played)
+			}
+		}
+		for _, listener := range m.listeners {
+			listener.MoveMade(g, played)
+		}
+		if claim {
+			g.Draw(g.Board.ClaimableDraw())
+		}
+	}
+}
+
+// printTimes reports the time each side spent on its moves in g
+func (m *GameManager) printTimes(g *Game, sources [2]MoveSource) {
+	if lines := g.TimeReport(sources); len(lines) > 0 {
+		fmt.Println("\nTime spent:")
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+	}
+}
+
+// draw ends g in a draw when the side to move can claim one, and otherwise
+// offers one to the opponent
+func (m *GameManager) draw(g *Game, sources [2]MoveSource) {
+	player := g.Board.ToMove()
+	if reason := g.Board.ClaimableDraw(); reason != InProgress {
+		g.Draw(reason)
+		return
+	}
+	if opponent, ok := sources[1-player].(DrawResponder); ok && opponent.AcceptDraw(g) {
+		g.Draw(Agreement)
+		return
+	}
+	m.notice = fmt.Sprintf("%s declines the draw offer.", 1-player)
+	if moves := g.Board.DrawClaimMoves(); len(moves) > 0 {
+		var sans []string
+		for _, move := range moves {
+			sans = append(sans, g.Board.SAN(move))
+		}
+		m.notice += fmt.Sprintf(" %s can claim a draw by playing %s: type 'draw <move>'.", player, strings.Join(sans, " or "))
+	}
+}
+
+// undo takes back the last move of g, and the engine's reply before it so
+// that a human player gets the move back. Game.Undo restores the board
+// from the moves themselves and the time spent from the clocks it saved
+// before each move.
+func (m *GameManager) undo(g *Game, sources [2]MoveSource) error {
+	for _, source := range sources {
+		if _, ok := source.(*NetworkSource); ok {
+			return fmt.Errorf("moves can't be taken back in a network game")
+		}
+	}
+	if err := g.Undo(); err != nil {
+		return err
+	}
+	if _, human := sources[g.Board.ToMove()].(*HumanSource); !human && len(g.Moves) > 0 {
+		g.Undo()
+	}
+	for _, listener := range m.listeners {
+		if l, ok := listener.(UndoListener); ok {
+			l.MoveUndone(g)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import "fmt"
+
+// Match keeps the running score of a game and its rematches. Players keep
+// their seat as they change colours: seat 0 is whoever played White in the
+// first game.
+type Match struct {
+	Start  string    // FEN every game of the match starts from
+	specs  [2]string // Move source of each seat, as for -white and -black
+	names  [2]string // Kind of player in each seat, for the score
+	swap   bool      // The seats change colours between games
+	white  int       // Seat playing White in the current game
+	points [2]int    // Half points, so a draw adds 1 to each seat
+	games  int
+}
+
+// NewMatch starts a match with g, played by sources created from specs.
+// Against the engine, or any other kind of player, the colours are swapped
+// for each rematch. Two people at the same keyboard keep theirs, and so do
+// the players of a network game, since the peer only plays the side it
+// connected for.
+func NewMatch(g *Game, specs [2]string, sources [2]MoveSource) *Match {
+	m := &Match{Start: g.Start, specs: specs, swap: true}
+	_, whiteHuman := sources[White].(*HumanSource)
+	_, blackHuman := sources[Black].(*HumanSource)
+	if whiteHuman && blackHuman {
+		m.swap = false
+	}
+	for seat, source := range sources {
+		if _, ok := source.(*NetworkSource); ok {
+			m.swap = false
+		}
+		m.names[seat] = sourceKind(source)
+	}
+	return m
+}
+
+// Record adds the result of g, the current game, to the score. Games still
+// in progress don't count.
+func (m *Match) Record(g *Game) {
+	result := g.Result()
+	if !result.IsOver() {
+		return
+	}
+	m.games++
+	if result.IsDraw() {
+		m.points[0]++
+		m.points[1]++
+		return
+	}
+	winner := m.white
+	if result.Winner == Black {
+		winner = 1 - m.white
+	}
+	m.points[winner] += 2
+}
+
+// Rematch moves on to the next game and returns the move source specs for
+// White and Black in it
+func (m *Match) Rematch() [2]string {
+	if m.swap {
+		m.white = 1 - m.white
+	}
+	return [2]string{m.specs[m.white], m.specs[1-m.white]}
+}
+
+// Score describes the match so far, e.g. "Match score after 2 games:
+// human 1.5, engine 0.5"
+func (m *Match) Score() string {
+	names := m.names
+	if names[0] == names[1] {
+		names = [2]string{"player 1", "player 2"}
+	}
+	games := "games"
+	if m.games == 1 {
+		games = "game"
+	}
+	return fmt.Sprintf("Match score after %d %s: %s %s, %s %s", m.games, games,
+		names[0], halfPoints(m.points[0]), names[1], halfPoints(m.points[1]))
+}
+
+// halfPoints formats a score counted in half points, e.g. 3 as "1.5"
+func halfPoints(n int) string {
+	if n%2 == 0 {
+		return fmt.Sprint(n / 2)
+	}
+	return fmt.Sprintf("%d.5", n/2)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+func TestMatchRematch(t *testing.T) {
+	conn, peer := net.Pipe()
+	defer conn.Close()
+	defer peer.Close()
+	human := &HumanSource{}
+	tests := []struct {
+		name    string
+		specs   [2]string
+		sources [2]MoveSource
+		second  [2]string // White and Black in the rematch
+		score   string    // After White wins both games
+	}{
+		{"engine", [2]string{"human", "ai"}, [2]MoveSource{human, &AISource{}}, [2]string{"ai", "human"},
+			"Match score after 2 games: human 1, engine 1"},
+		{"hotseat", [2]string{"human", "human"}, [2]MoveSource{human, human}, [2]string{"human", "human"},
+			"Match score after 2 games: player 1 2, player 2 0"},
+		{"network", [2]string{"human", "connect:localhost:9000"}, [2]MoveSource{human, NewNetworkSource(conn, Black)}, [2]string{"human", "connect:localhost:9000"},
+			"Match score after 2 games: human 2, network 0"},
+	}
+	for _, tt := range tests {
+		first, err := NewGameFromFEN("4k3/8/8/8/8/8/8/R3K3 w Q - 0 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		match := NewMatch(first, tt.specs, tt.sources)
+		first.Resign(Black)
+		match.Record(first)
+		if got := match.Rematch(); got != tt.second {
+			t.Errorf("%s: rematch %v, want %v", tt.name, got, tt.second)
+		}
+		if match.Start != first.Start {
+			t.Errorf("%s: rematch starts from %s, want %s", tt.name, match.Start, first.Start)
+		}
+		second, err := NewGameFromFEN(match.Start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second.Resign(Black)
+		match.Record(second)
+		if got := match.Score(); got != tt.score {
+			t.Errorf("%s: %q, want %q", tt.name, got, tt.score)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MenuChoice is the game picked in the startup menu
+type MenuChoice struct {
+	White, Black string // Move sources, as for -white and -black
+	Depth        int
+	Resume       string // Game log to resume, if any
+	FEN          string // Starting position, if not the standard one
+}
+
+// showMenu reports whether to show the startup menu: only when the program
+// was started without flags by a person at a terminal, so scripts and
+// piped input go straight to the game
+func showMenu() bool {
+	if flag.NFlag() > 0 {
+		return false
+	}
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// RunMenu shows the startup menu until a game is picked. It returns false
+// if the player quits instead, or the input ends.
+func RunMenu(scanner *bufio.Scanner) (MenuChoice, bool) {
+	choice := MenuChoice{White: "human", Black: "human", Depth: *depthFlag}
+	for {
+		fmt.Println("\nTerminal Chess")
+		fmt.Println()
+		fmt.Println("1) New game for two players")
+		fmt.Println("2) Play against the computer")
+		fmt.Println("3) Load a saved game")
+		fmt.Println("4) Set up a position")
+		fmt.Println("5) Quit")
+
+		picked, ok := menuPrompt(scanner, "\nChoose 1-5: ")
+		if !ok {
+			return choice, false
+		}
+		switch picked {
+		case "1":
+			return choice, true
+		case "2":
+			color, ok := menuPrompt(scanner, "Play as White or Black? (w/b) [w]: ")
+			if !ok {
+				return choice, false
+			}
+			if color = strings.ToLower(color); color == "b" || color == "black" {
+				choice.White = "ai"
+			} else {
+				choice.Black = "ai"
+			}
+			answer, ok := menuPrompt(scanner, fmt.Sprintf("Search depth, higher is stronger and slower [%d]: ", choice.Depth))
+			if !ok {
+				return choice, false
+			}
+			if depth, err := strconv.Atoi(answer); err == nil && depth > 0 {
+				choice.Depth = depth
+			}
+			return choice, true
+		case "3":
+			path, ok := menuPrompt(scanner, "Game log file (as written with -db): ")
+			if !ok {
+				return choice, false
+			}
+			if _, err := LoadGameLog(path); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			choice.Resume = path
+			return choice, true
+		case "4":
+			fen, ok := menuPrompt(scanner, "FEN: ")
+			if !ok {
+				return choice, false
+			}
+			if _, err := BoardFromFEN(fen); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			choice.FEN = fen
+			return choice, true
+		case "5", "q", "quit":
+			return choice, false
+		}
+	}
+}
+
+// menuPrompt asks for one line of input. It returns false when the input
+// ends or can't be read.
+func menuPrompt(scanner *bufio.Scanner, prompt string) (string, bool) {
+	fmt.Print(prompt)
+	if !scanner.Scan() {
+		return "", false
+	}
+	return strings.TrimSpace(scanner.Text()), true
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestRunMenu(t *testing.T) {
+	tests := []struct {
+		input string
+		want  MenuChoice
+		ok    bool
+	}{
+		{"1\n", MenuChoice{White: "human", Black: "human", Depth: *depthFlag}, true},
+		{"\n1\n", MenuChoice{White: "human", Black: "human", Depth: *depthFlag}, true}, // Empty lines show the menu again
+		{"2\nb\n5\n", MenuChoice{White: "ai", Black: "human", Depth: 5}, true},
+		{"2\n\n\n", MenuChoice{White: "human", Black: "ai", Depth: *depthFlag}, true},
+		{"4\nnot a fen\n4\n4k3/8/8/8/8/8/8/4K3 w - - 0 1\n", MenuChoice{White: "human", Black: "human", Depth: *depthFlag, FEN: "4k3/8/8/8/8/8/8/4K3 w - - 0 1"}, true},
+		{"9\n5\n", MenuChoice{White: "human", Black: "human", Depth: *depthFlag}, false},
+		{"", MenuChoice{White: "human", Black: "human", Depth: *depthFlag}, false},
+		{"\n", MenuChoice{White: "human", Black: "human", Depth: *depthFlag}, false},
+		{"2\nw\n", MenuChoice{White: "human", Black: "ai", Depth: *depthFlag}, false}, // Input ends at the depth prompt
+	}
+	for _, tt := range tests {
+		got, ok := RunMenu(bufio.NewScanner(strings.NewReader(tt.input)))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("RunMenu(%q) = %+v, %v, want %+v, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
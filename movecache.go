@@ -0,0 +1,79 @@
+package main
+
+import "sync"
+
+// legalMoveCacheSize bounds the number of positions kept by the legal move
+// cache; the cache is emptied when it fills up
+const legalMoveCacheSize = 1 << 16
+
+// cachedMove is what the cache keeps of a legal move
+type cachedMove struct {
+	from, to  Position
+	promotion PieceType
+}
+
+type legalMoveKey struct {
+	hash   uint64
+	player Player
+	rules  Rules // Castling and en passant can be switched off
+}
+
+// legalMoveCache remembers the legal moves found in each position. Only the
+// squares and promotion are kept: a move carries undo state, such as the
+// halfmove clock and previous move, that differs between transpositions, so
+// cached moves are rebuilt against the current board on a hit.
+var legalMoveCache = struct {
+	sync.Mutex
+	entries      map[legalMoveKey][]cachedMove
+	hits, misses int
+}{entries: make(map[legalMoveKey][]cachedMove)}
+
+// cachedLegalMoves returns the legal moves for player from the cache, or
+// false if the position has not been seen
+func (b *Board) cachedLegalMoves(key legalMoveKey, player Player) ([]Move, bool) {
+	legalMoveCache.Lock()
+	squares, ok := legalMoveCache.entries[key]
+	if ok {
+		legalMoveCache.hits++
+	} else {
+		legalMoveCache.misses++
+	}
+	legalMoveCache.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	moves := make([]Move, 0, len(squares))
+	for _, sq := range squares {
+		move, err := b.ValidateMove(sq.from, sq.to, player)
+		if err != nil {
+			// Hash collision with a different position
+			return nil, false
+		}
+		move.Promotion = sq.promotion
+		moves = append(moves, move)
+	}
+	return moves, true
+}
+
+// cacheLegalMoves stores the legal moves found for the position
+func cacheLegalMoves(key legalMoveKey, moves []Move) {
+	squares := make([]cachedMove, len(moves))
+	for i, move := range moves {
+		squares[i] = cachedMove{move.From, move.To, move.Promotion}
+	}
+
+	legalMoveCache.Lock()
+	defer legalMoveCache.Unlock()
+	if len(legalMoveCache.entries) >= legalMoveCacheSize {
+		legalMoveCache.entries = make(map[legalMoveKey][]cachedMove)
+	}
+	legalMoveCache.entries[key] = squares
+}
+
+// LegalMoveCacheStats returns the number of cache hits and misses so far
+func LegalMoveCacheStats() (hits, misses int) {
+	legalMoveCache.Lock()
+	defer legalMoveCache.Unlock()
+	return legalMoveCache.hits, legalMoveCache.misses
+}
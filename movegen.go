@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+	"strings"
+)
+
+// LegalMoves returns every legal move for player. Results are cached by
+// position, so positions that recur in search, analysis or replay are fast.
+func (b *Board) LegalMoves(player Player) []Move {
+	key := legalMoveKey{b.Hash(), player, b.Rules()}
+	if moves, ok := b.cachedLegalMoves(key, player); ok {
+		return moves
+	}
+
+	var moves []Move
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece != nil && piece.Player == player {
+				moves = append(moves, b.LegalMovesFrom(Position{row, col})...)
+			}
+		}
+	}
+	cacheLegalMoves(key, moves)
+	return moves
+}
+
+// LegalMovesFrom returns every legal move for the piece on pos. Each
+// candidate is made on the board and dropped if it leaves the mover's king
+// attacked, so pinned pieces, including an en passant capture that opens
+// the king's rank, never move out of line. Checks by a piece the move
+// uncovers need no special case either: they are ordinary attacks on the
+// opponent's king once the move is made.
+func (b *Board) LegalMovesFrom(pos Position) []Move {
+	piece := b.squares[pos.Row][pos.Col]
+	if piece == nil {
+		return nil
+	}
+
+	var moves []Move
+	for _, to := range b.pieceTargets(piece, pos) {
+		move, err := b.ValidateMove(pos, to, piece.Player)
+		if err != nil {
+			continue
+		}
+		b.makeMove(move)
+		inCheck := b.IsInCheck(piece.Player)
+		b.undoMove(move)
+		if inCheck {
+			continue
+		}
+		if move.Promotion == Pawn {
+			moves = append(moves, move)
+			continue
+		}
+		for _, pt := range promotionChoices {
+			move.Promotion = pt
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// KingEscapes returns the squares player's king can legally move to. While
+// in check these are the escapes from it; when there are none, the check
+// can only be answered by capturing or blocking the checking piece.
+func (b *Board) KingEscapes(player Player) []Position {
+	king := b.whiteKing
+	if player == Black {
+		king = b.blackKing
+	}
+	var squares []Position
+	for _, move := range b.LegalMovesFrom(king) {
+		squares = append(squares, move.To)
+	}
+	return squares
+}
+
+// MoveToSquare returns player's move to to, for input that only names the
+// destination square. It fails when no piece or more than one piece can
+// move there, listing the candidate moves in the latter case.
+func (b *Board) MoveToSquare(to Position, player Player) (Move, error) {
+	var froms []Position
+	for _, move := range b.LegalMoves(player) {
+		if move.To == to && !slices.Contains(froms, move.From) {
+			froms = append(froms, move.From)
+		}
+	}
+
+	switch len(froms) {
+	case 0:
+		return Move{}, fmt.Errorf("no piece can move to %s", to)
+	case 1:
+		return Move{From: froms[0], To: to}, nil
+	}
+	candidates := make([]string, len(froms))
+	for i, from := range froms {
+		candidates[i] = fmt.Sprintf("%s-%s", from, to)
+	}
+	return Move{}, fmt.Errorf("more than one piece can move to %s, enter one of: %s", to, strings.Join(candidates, ", "))
+}
+
+var (
+	knightOffsets = [][2]int{{-2, -1}, {-2, 1}, {-1, -2}, {-1, 2}, {1, -2}, {1, 2}, {2, -1}, {2, 1}}
+	kingOffsets   = [][2]int{{-1, -1}, {-1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, -1}, {1, 0}, {1, 1}}
+	rookRays      = [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+	bishopRays    = [][2]int{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}}
+)
+
+// pieceTargets returns the squares the piece on pos can reach by its
+// movement pattern alone: each step or ray is followed only as far as the
+// first piece in the way, and squares held by its own side are left out.
+// ValidateMove still has the final say, but this spares it the squares the
+// piece could never reach. Steps and rays come from the attack tables, in
+// the same order as the offsets, nearest square first along each ray.
+func (b *Board) pieceTargets(piece *Piece, pos Position) []Position {
+	var targets []Position
+	sq := squareIndex(pos)
+	own := b.bb.occupied[piece.Player]
+	add := func(set uint64, descending bool) {
+		for set != 0 {
+			i := bits.TrailingZeros64(set)
+			if descending {
+				i = 63 - bits.LeadingZeros64(set)
+			}
+			set &^= 1 << i
+			targets = append(targets, Position{i / 8, i % 8})
+		}
+	}
+	step := func(offsets [][2]int) {
+		for _, o := range offsets {
+			to := Position{pos.Row + o[0], pos.Col + o[1]}
+			if isValidPosition(to) {
+				targets = append(targets, to)
+			}
+		}
+	}
+	slide := func(first, last int) {
+		for d := first; d <= last; d++ {
+			r := rayDirections[d]
+			add(b.rayAttacks(sq, d)&^own, r[0]*8+r[1] < 0)
+		}
+	}
+	rook, bishop := len(rookRays), len(rookRays)+len(bishopRays)
+
+	switch piece.Type {
+	case Pawn:
+		forward := -1
+		if piece.Player == Black {
+			forward = 1
+		}
+		step([][2]int{{forward, -1}, {forward, 0}, {forward, 1}, {2 * forward, 0}})
+	case Knight:
+		add(knightAttacks[sq]&^own, false)
+	case Bishop:
+		slide(rook, bishop-1)
+	case Rook:
+		slide(0, rook-1)
+	case Queen:
+		slide(0, bishop-1)
+	case King:
+		add(kingAttacks[sq]&^own, false)
+		step([][2]int{{0, -2}, {0, 2}}) // Castling
+	}
+	return targets
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// moveGenPositions are the standard perft test positions, covering
+// castling, pins, en passant and promotions, and a few positions for the
+// tricky cases
+var moveGenPositions = []string{
+	"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	"8/8/8/K2pP2r/8/8/8/7k w - d6 0 1",    // En passant would expose the king
+	"1r2k3/8/8/8/8/8/8/R3K3 w Q - 0 1",    // Castling past an attacked b-file
+	"4k3/4n3/8/8/8/8/8/4RK2 b - - 0 1",    // Pinned knight
+	"r3k3/1P6/8/8/8/8/6p1/4K2R w K - 0 1", // Promotions with capture
+}
+
+// moveGenDepth is how many plies deep the positions are walked
+const moveGenDepth = 2
+
+// walkPositions calls check on every position reachable within
+// moveGenDepth plies of moveGenPositions, stopping at the first failure
+func walkPositions(t *testing.T, check func(b *Board) bool) {
+	t.Helper()
+	var walk func(b *Board, depth int) bool
+	walk = func(b *Board, depth int) bool {
+		if !check(b) {
+			return false
+		}
+		if depth == 0 {
+			return true
+		}
+		for _, move := range b.LegalMoves(b.ToMove()) {
+			b.makeMove(move)
+			ok := walk(b, depth-1)
+			b.undoMove(move)
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	for _, fen := range moveGenPositions {
+		board, err := BoardFromFEN(fen)
+		if err != nil {
+			t.Fatalf("%s: %v", fen, err)
+		}
+		walk(board, moveGenDepth)
+	}
+}
+
+// legalMovesByScan is the reference move generator: it tries every square
+// of the board as a destination for every piece of player. It is slow but
+// independent of pieceTargets.
+func (b *Board) legalMovesByScan(player Player) []Move {
+	var moves []Move
+	for from := 0; from < 64; from++ {
+		pos := Position{from / 8, from % 8}
+		if piece := b.squares[pos.Row][pos.Col]; piece == nil || piece.Player != player {
+			continue
+		}
+		for to := 0; to < 64; to++ {
+			move, err := b.CheckMove(pos, Position{to / 8, to % 8}, player)
+			if err != nil {
+				continue
+			}
+			if move.Promotion == Pawn {
+				moves = append(moves, move)
+				continue
+			}
+			for _, pt := range promotionChoices {
+				move.Promotion = pt
+				moves = append(moves, move)
+			}
+		}
+	}
+	return moves
+}
+
+// moveNames returns the moves in coordinate notation, sorted
+func moveNames(moves []Move) []string {
+	names := make([]string, len(moves))
+	for i, move := range moves {
+		names[i] = move.String()
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestLegalMovesMatchScan(t *testing.T) {
+	walkPositions(t, func(b *Board) bool {
+		player := b.ToMove()
+		got, want := moveNames(b.LegalMoves(player)), moveNames(b.legalMovesByScan(player))
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: generated %v, want %v", b.ToFEN(), got, want)
+			return false
+		}
+		return true
+	})
+}
+
+func TestPins(t *testing.T) {
+	checkLegality(t, []legalityTest{
+		{"4k3/8/8/8/4n3/8/8/K3R3 b - - 0 1", "e4-c3", false}, // Knight pinned on the file
+		{"4k3/8/8/8/4n3/8/8/K2R4 b - - 0 1", "e4-c3", true},  // free once the rook is off it
+		{"4k3/4r3/8/8/8/8/8/K3R3 b - - 0 1", "e7-e5", true},  // A pinned rook moves along the pin
+		{"4k3/4r3/8/8/8/8/8/K3R3 b - - 0 1", "e7-e1", true},  // or takes the pinning piece
+		{"4k3/4r3/8/8/8/8/8/K3R3 b - - 0 1", "e7-d7", false}, // but not out of line
+		{"4k3/8/8/b7/8/2N5/8/4K3 w - - 0 1", "c3-e4", false}, // Knight pinned on the diagonal
+		{"4k3/8/8/b7/8/2B5/8/4K3 w - - 0 1", "c3-b4", true},  // A bishop moves along it
+		{"8/8/8/K2pP2r/8/8/8/7k w - d6 0 1", "e5-d6", false}, // En passant would open the rank
+		{"8/8/8/K2pP3/7r/8/8/7k w - d6 0 1", "e5-d6", true},
+	})
+}
+
+func TestDiscoveredChecks(t *testing.T) {
+	tests := []struct {
+		fen, move string
+	}{
+		{"4k3/8/8/8/4N3/8/8/K3R3 w - - 0 1", "e4-c3"}, // Knight uncovers the rook
+		{"4k3/8/8/8/4N3/8/8/K3R3 w - - 0 1", "e4-g5"},
+		{"7k/8/8/8/3P4/8/1B6/K7 w - - 0 1", "d4-d5"},    // Pawn uncovers the bishop
+		{"k7/8/8/8/8/8/8/K2b3q b - - 0 1", "d1-f3"},     // Bishop uncovers the queen along the rank
+		{"4k3/8/8/3pP3/8/8/8/4QK2 w - d6 0 1", "e5-d6"}, // En passant opens the file
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		player := board.ToMove()
+		move := playMove(t, board, tt.move)
+		if !board.IsInCheck(1 - player) {
+			t.Errorf("%s: %s gives no check", tt.fen, tt.move)
+		}
+		board.undoMove(move)
+		if board.IsInCheck(1 - player) {
+			t.Errorf("%s: in check before %s", tt.fen, tt.move)
+		}
+	}
+}
+
+func TestMoveToSquare(t *testing.T) {
+	start := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	tests := []struct {
+		fen, to string
+		want    string // The move, or the error
+	}{
+		{start, "e4", "e2-e4"},
+		{start, "f3", "more than one piece can move to f3, enter one of: f2-f3, g1-f3"},
+		{start, "e5", "no piece can move to e5"},
+		// A square held by one's own piece is no destination
+		{start, "e2", "no piece can move to e2"},
+		// Both knights reach f3, but the one on d2 is pinned to its king
+		{"4k3/8/8/8/1b6/8/3N4/4K1N1 w - - 0 1", "f3", "g1-f3"},
+		{"4k3/8/8/8/1b6/8/3N4/4K3 w - - 0 1", "f3", "no piece can move to f3"},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		to, err := ParseSquare(tt.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if move, err := board.MoveToSquare(to, board.ToMove()); err != nil {
+			got = err.Error()
+		} else {
+			got = move.String()
+		}
+		if got != tt.want {
+			t.Errorf("%s: MoveToSquare(%s) = %q, want %q", tt.fen, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// moveClock is the wall-clock time one side spent on its moves: from asking
+// its move source for a move until the move came back. It is kept even
+// without chess clocks, for the report at the end of the game.
+type moveClock struct {
+	total time.Duration
+	moves int
+}
+
+// sourceKind describes a move source in the time report, which matters
+// because an engine's time is computation and a human's is thinking
+func sourceKind(source MoveSource) string {
+	switch source.(type) {
+	case *HumanSource:
+		return "human"
+	case *AISource:
+		return "engine"
+	case *RandomSource:
+		return "random"
+	case *ScriptedSource:
+		return "script"
+	case *FIFOSource:
+		return "pipe"
+	case *NetworkSource:
+		return "network"
+	}
+	return "other"
+}
+
+// TimeReport returns a line per side with the time it spent on its moves
+// in total and on average
+func (g *Game) TimeReport(sources [2]MoveSource) []string {
+	var lines []string
+	for _, player := range []Player{White, Black} {
+		clock := g.clocks[player]
+		if clock.moves == 0 {
+			continue
+		}
+		if clock.moves == 1 {
+			lines = append(lines, fmt.Sprintf("%s (%s): %s for 1 move", player, sourceKind(sources[player]), roundDuration(clock.total)))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s (%s): %s for %d moves, %s per move", player, sourceKind(sources[player]),
+			roundDuration(clock.total), clock.moves, roundDuration(clock.total/time.Duration(clock.moves))))
+	}
+	return lines
+}
+
+// roundDuration rounds d to tenths of a second, or milliseconds for the
+// short times engines take
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(100 * time.Millisecond)
+}
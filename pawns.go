@@ -0,0 +1,59 @@
+package main
+
+// Pawn structure helpers used by the evaluation
+
+// pawnsOnFile returns the number of player's pawns on the file col
+func (b *Board) pawnsOnFile(player Player, col int) int {
+	n := 0
+	for row := 0; row < 8; row++ {
+		piece := b.squares[row][col]
+		if piece != nil && piece.Type == Pawn && piece.Player == player {
+			n++
+		}
+	}
+	return n
+}
+
+// isOpenFile reports whether the file has no pawns of either side
+func (b *Board) isOpenFile(col int) bool {
+	return b.pawnsOnFile(White, col) == 0 && b.pawnsOnFile(Black, col) == 0
+}
+
+// isHalfOpenFile reports whether the file has opponent pawns but none of
+// player's own
+func (b *Board) isHalfOpenFile(player Player, col int) bool {
+	return b.pawnsOnFile(player, col) == 0 && b.pawnsOnFile(1-player, col) > 0
+}
+
+// isPassedPawn reports whether no opponent pawn can stop or capture the
+// pawn on pos on its way to promotion
+func (b *Board) isPassedPawn(pos Position) bool {
+	pawn := b.squares[pos.Row][pos.Col]
+	forward := -1
+	if pawn.Player == Black {
+		forward = 1
+	}
+	for row := pos.Row + forward; row >= 0 && row < 8; row += forward {
+		for col := max(pos.Col-1, 0); col <= min(pos.Col+1, 7); col++ {
+			piece := b.squares[row][col]
+			if piece != nil && piece.Type == Pawn && piece.Player != pawn.Player {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// PassedPawns returns the squares of player's passed pawns
+func (b *Board) PassedPawns(player Player) []Position {
+	var passed []Position
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece != nil && piece.Type == Pawn && piece.Player == player && b.isPassedPawn(Position{row, col}) {
+				passed = append(passed, Position{row, col})
+			}
+		}
+	}
+	return passed
+}
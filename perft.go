@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Perft counts the leaf nodes of the legal move tree depth plies deep, the
+// standard check of move generation correctness and speed
+func (b *Board) Perft(depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	nodes := 0
+	for _, move := range b.LegalMoves(b.ToMove()) {
+		b.makeMove(move)
+		nodes += b.Perft(depth - 1)
+		b.undoMove(move)
+	}
+	return nodes
+}
+
+// benchPositions are the positions run by Bench: the start position and a
+// middlegame with castling, pins and captures available
+var benchPositions = []string{
+	"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+}
+
+// benchDepth is the perft depth run by Bench
+const benchDepth = 3
+
+// Bench runs a fixed perft and search over benchPositions and prints the
+// node counts and speed, as a baseline for performance changes
+func Bench() error {
+	totalNodes := 0
+	start := time.Now()
+	for _, fen := range benchPositions {
+		board, err := BoardFromFEN(fen)
+		if err != nil {
+			return err
+		}
+
+		t := time.Now()
+		nodes := board.Perft(benchDepth)
+		fmt.Printf("perft(%d) %8d nodes %8.0f nodes/sec  %s\n", benchDepth, nodes, float64(nodes)/time.Since(t).Seconds(), fen)
+		totalNodes += nodes
+
+		t = time.Now()
+		if _, _, err := board.Search(benchDepth); err != nil {
+			return err
+		}
+		searched := board.NodesSearched()
+		fmt.Printf("search(%d) %7d nodes %8.0f nodes/sec\n", benchDepth, searched, float64(searched)/time.Since(t).Seconds())
+		totalNodes += searched
+	}
+	fmt.Printf("total %d nodes in %v, %.0f nodes/sec\n", totalNodes, time.Since(start).Round(time.Millisecond), float64(totalNodes)/time.Since(start).Seconds())
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import "testing"
+
+// Known perft counts for the standard test positions
+var perftTests = []struct {
+	name  string
+	fen   string
+	nodes []int // By depth, from 1
+}{
+	{"start", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", []int{20, 400, 8902, 197281}},
+	{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []int{48, 2039, 97862}},
+	{"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []int{14, 191, 2812, 43238}},
+	{"position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []int{6, 264, 9467}},
+	{"position 5", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []int{44, 1486, 62379}},
+}
+
+func TestPerft(t *testing.T) {
+	for _, tt := range perftTests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for i, want := range tt.nodes {
+			depth := i + 1
+			if testing.Short() && depth > 3 {
+				break
+			}
+			if got := board.Perft(depth); got != want {
+				t.Errorf("%s: perft(%d) = %d, want %d", tt.name, depth, got, want)
+			}
+		}
+		if got := board.ToFEN(); got != tt.fen {
+			t.Errorf("%s: perft left the board at %s", tt.name, got)
+		}
+	}
+}
+
+// clearLegalMoveCache empties the legal move cache, so that benchmarks
+// measure move generation rather than cache lookups
+func clearLegalMoveCache() {
+	legalMoveCache.Lock()
+	legalMoveCache.entries = make(map[legalMoveKey][]cachedMove)
+	legalMoveCache.Unlock()
+}
+
+func BenchmarkPerft(b *testing.B) {
+	for _, tt := range perftTests[:2] {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(tt.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				clearLegalMoveCache()
+				board.Perft(3)
+			}
+		})
+	}
+}
+
+func BenchmarkLegalMoves(b *testing.B) {
+	for _, tt := range perftTests[:2] {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(tt.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				clearLegalMoveCache()
+				board.LegalMoves(board.ToMove())
+			}
+		})
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	for _, tt := range perftTests[:2] {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(tt.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				clearLegalMoveCache()
+				if _, _, err := board.Search(3); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLegalMoveCache measures a search starting from an empty cache,
+// and again with the cache kept from earlier searches, as when the engine
+// thinks about the same game move after move. It reports the share of
+// legal move lookups the cache answered.
+func BenchmarkLegalMoveCache(b *testing.B) {
+	for _, tt := range perftTests[:2] {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, warm := range []bool{false, true} {
+			name := tt.name + "/cold"
+			if warm {
+				name = tt.name + "/warm"
+			}
+			b.Run(name, func(b *testing.B) {
+				clearLegalMoveCache()
+				hits0, misses0 := LegalMoveCacheStats()
+				for i := 0; i < b.N; i++ {
+					if !warm {
+						clearLegalMoveCache()
+					}
+					if _, _, err := board.Search(3); err != nil {
+						b.Fatal(err)
+					}
+				}
+				hits, misses := LegalMoveCacheStats()
+				hits, misses = hits-hits0, misses-misses0
+				b.ReportMetric(100*float64(hits)/float64(hits+misses), "%hits")
+			})
+		}
+	}
+}
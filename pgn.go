@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SplitComment separates a trailing move comment in braces from the move,
+// e.g. "e2-e4 {good central control}" yields "e2-e4" and "good central control"
+func SplitComment(input string) (notation, comment string) {
+	start := strings.IndexByte(input, '{')
+	if start < 0 {
+		return strings.TrimSpace(input), ""
+	}
+	comment = input[start+1:]
+	if end := strings.LastIndexByte(comment, '}'); end >= 0 {
+		comment = comment[:end]
+	}
+	return strings.TrimSpace(input[:start]), strings.TrimSpace(comment)
+}
+
+// PGN renders the game in Portable Game Notation. The moves are replayed from
+// the starting position to derive SAN, and move comments are emitted as {...}.
+func (g *Game) PGN() string {
+	result := g.Result().Token()
+	if result == "" {
+		result = "*"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Event \"Casual game\"]\n")
+	fmt.Fprintf(&sb, "[Site \"terminal_chess\"]\n")
+	fmt.Fprintf(&sb, "[Date \"%s\"]\n", time.Now().Format("2006.01.02"))
+	fmt.Fprintf(&sb, "[Round \"-\"]\n")
+	fmt.Fprintf(&sb, "[White \"White\"]\n")
+	fmt.Fprintf(&sb, "[Black \"Black\"]\n")
+	fmt.Fprintf(&sb, "[Result \"%s\"]\n", result)
+
+	board, err := BoardFromFEN(g.Start)
+	if err != nil {
+		board = NewBoard()
+	}
+	if fen := board.ToFEN(); fen != NewBoard().ToFEN() {
+		fmt.Fprintf(&sb, "[SetUp \"1\"]\n")
+		fmt.Fprintf(&sb, "[FEN \"%s\"]\n", fen)
+	}
+	sb.WriteByte('\n')
+
+	tokens := make([]string, 0, len(g.Moves)+1)
+	for i, m := range g.Moves {
+		move, err := board.ResolveMove(m, board.ToMove())
+		if err != nil {
+			break
+		}
+		token := board.strictSAN(move)
+		if board.ToMove() == White {
+			token = fmt.Sprintf("%d. %s", board.moveCount/2+1, token)
+		} else if i == 0 {
+			token = fmt.Sprintf("%d... %s", board.moveCount/2+1, token)
+		}
+		board.makeMove(move)
+		board.recordPosition()
+		tokens = append(tokens, token)
+		if m.Comment != "" {
+			// A closing brace would end the comment early
+			tokens = append(tokens, "{"+strings.ReplaceAll(m.Comment, "}", ")")+"}")
+		}
+	}
+	tokens = append(tokens, result)
+
+	// Wrap movetext at 80 columns
+	lineLen := 0
+	for _, token := range tokens {
+		if lineLen > 0 && lineLen+1+len(token) > 80 {
+			sb.WriteByte('\n')
+			lineLen = 0
+		} else if lineLen > 0 {
+			sb.WriteByte(' ')
+			lineLen++
+		}
+		sb.WriteString(token)
+		lineLen += len(token)
+	}
+	sb.WriteByte('\n')
+
+	return sb.String()
+}
+
+// PGNGame is a game read from PGN: its tag pairs and the SAN moves of its
+// main line, without move numbers, comments, NAGs or variations
+type PGNGame struct {
+	Tags   map[string]string
+	Moves  []string
+	Result string // "1-0", "0-1", "1/2-1/2" or "*"
+}
+
+// ParsePGN reads the games in PGN text. A game ends with its result token,
+// or with the end of the text if that is missing.
+func ParsePGN(text string) ([]PGNGame, error) {
+	text = strings.TrimPrefix(text, byteOrderMark)
+	var games []PGNGame
+	game := PGNGame{Tags: map[string]string{}}
+	started := false
+	finish := func(result string) {
+		game.Result = result
+		games = append(games, game)
+		game = PGNGame{Tags: map[string]string{}}
+		started = false
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '[':
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated tag pair")
+			}
+			name, value, _ := strings.Cut(text[i+1:i+end], " ")
+			game.Tags[name] = strings.Trim(strings.TrimSpace(value), `"`)
+			started = true
+			i += end + 1
+		case c == '{':
+			end := strings.IndexByte(text[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 1
+		case c == ';':
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text) - i
+			}
+			i += end
+		case c == '(':
+			// Skip the variation, including any nested in it
+			depth := 0
+			for ; i < len(text); i++ {
+				if text[i] == '(' {
+					depth++
+				} else if text[i] == ')' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			if depth > 0 {
+				return nil, fmt.Errorf("unterminated variation")
+			}
+			i++
+		default:
+			end := i
+			for end < len(text) && !strings.ContainsRune(" \t\r\n[]{};()", rune(text[end])) {
+				end++
+			}
+			token := text[i:end]
+			i = end
+
+			switch token {
+			case "1-0", "0-1", "1/2-1/2", "*":
+				finish(token)
+				continue
+			}
+			if token[0] == '$' {
+				continue
+			}
+			// Move numbers, possibly written against the move as in "1.e4"
+			if rest := strings.TrimLeft(token, "0123456789"); rest != token && strings.HasPrefix(rest, ".") {
+				token = strings.TrimLeft(rest, ".")
+			}
+			if token != "" {
+				game.Moves = append(game.Moves, token)
+				started = true
+			}
+		}
+	}
+	if started {
+		finish("*")
+	}
+	return games, nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// playGame plays moves in coordinate notation, with optional comments, from
+// the position fen
+func playGame(t *testing.T, fen string, moves []string) *Game {
+	t.Helper()
+	g, err := NewGameFromFEN(fen)
+	if err != nil {
+		t.Fatalf("%s: %v", fen, err)
+	}
+	for _, input := range moves {
+		notation, comment := SplitComment(input)
+		parsed, err := ParseCoordinateMove(notation)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		move, err := g.Board.ResolveMove(parsed, g.Board.ToMove())
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		move.Comment = comment
+		if err := g.Play(move); err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+	}
+	return g
+}
+
+func TestPGNRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		moves []string
+		san   []string
+	}{
+		{
+			"castling and en passant",
+			"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			[]string{"e2-e4 {king's pawn}", "g8-f6", "e4-e5", "d7-d5", "e5-d6", "e7-d6", "g1-f3", "f8-e7", "f1-c4", "e8-g8", "e1-g1"},
+			[]string{"e4", "Nf6", "e5", "d5", "exd6", "exd6", "Nf3", "Be7", "Bc4", "O-O", "O-O"},
+		},
+		{
+			"underpromotion from a set-up position with Black to move",
+			"4k3/8/8/8/8/8/p6P/4K3 b - - 0 40",
+			[]string{"a2-a1n", "h2-h4", "a1-b3"},
+			[]string{"a1=N", "h4", "Nb3"},
+		},
+		{
+			"disambiguation and mate",
+			"6k1/5ppp/8/8/8/8/5PPP/R3R1K1 w - - 0 1",
+			[]string{"a1-d1", "g8-f8", "d1-d8"},
+			[]string{"Rad1", "Kf8", "Rd8#"},
+		},
+	}
+	for _, tt := range tests {
+		g := playGame(t, tt.fen, tt.moves)
+		text := g.PGN()
+		games, err := ParsePGN(text)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", tt.name, err, text)
+		}
+		if len(games) != 1 {
+			t.Fatalf("%s: parsed %d games from\n%s", tt.name, len(games), text)
+		}
+		if !slices.Equal(games[0].Moves, tt.san) {
+			t.Errorf("%s: PGN moves %v, want %v\n%s", tt.name, games[0].Moves, tt.san, text)
+		}
+
+		// Replaying the SAN reaches the same position
+		start := tt.fen
+		if fen, ok := games[0].Tags["FEN"]; ok {
+			start = fen
+		} else if start != NewBoard().ToFEN() {
+			t.Errorf("%s: no FEN tag for a set-up position\n%s", tt.name, text)
+		}
+		board, err := BoardFromFEN(start)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for _, san := range games[0].Moves {
+			move, err := board.ParseSAN(san)
+			if err != nil {
+				t.Fatalf("%s: %s: %v", tt.name, san, err)
+			}
+			board.makeMove(move)
+		}
+		if got, want := board.ToFEN(), g.Board.ToFEN(); got != want {
+			t.Errorf("%s: replayed to %s, want %s", tt.name, got, want)
+		}
+		if strings.Contains(tt.moves[0], "{") && !strings.Contains(text, "{king's pawn}") {
+			t.Errorf("%s: comment missing from\n%s", tt.name, text)
+		}
+	}
+}
+
+func TestParseSAN(t *testing.T) {
+	tests := []struct {
+		fen, san, want string
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "Nf3", "g1-f3"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e4!?", "e2-e4"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "O-O-O", "e1-c1"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "0-0", "e8-g8"},
+		{"6k1/5ppp/8/8/8/8/5PPP/R3R1K1 w - - 0 1", "Rad1", "a1-d1"},
+		{"6k1/5ppp/8/8/8/8/5PPP/R3R1K1 w - - 0 1", "Ra1d1", "a1-d1"},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8=R+", "a7-a8r"},
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "exd6", "e5-d6"},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		move, err := board.ParseSAN(tt.san)
+		if err != nil {
+			t.Errorf("%s: ParseSAN(%q): %v", tt.fen, tt.san, err)
+			continue
+		}
+		if move.String() != tt.want {
+			t.Errorf("%s: ParseSAN(%q) = %s, want %s", tt.fen, tt.san, move, tt.want)
+		}
+	}
+
+	// Ambiguous and illegal moves are rejected
+	board, _ := BoardFromFEN("6k1/5ppp/8/8/8/8/5PPP/R3R1K1 w - - 0 1")
+	for _, san := range []string{"Rd1", "Rd2", "Qd1", "e5"} {
+		if move, err := board.ParseSAN(san); err == nil {
+			t.Errorf("ParseSAN(%q) = %s, want an error", san, move)
+		}
+	}
+}
+
+func TestMoveNumbersFromFEN(t *testing.T) {
+	// Black to move at move 10: the first move is 10... and White's reply
+	// starts move 11 in every place the moves are numbered
+	fen := "r1bqkbnr/pppppppp/2n5/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 10"
+	g := playGame(t, fen, []string{"e7-e5", "g1-f3", "g8-f6"})
+
+	if got, want := g.SANHistory(false), []string{"10... e5", "11. Nf3 Nf6"}; !slices.Equal(got, want) {
+		t.Errorf("SAN history %q, want %q", got, want)
+	}
+	if got, want := g.historyLines(), []string{"10... e7-e5", "11. g1-f3 g8-f6"}; !slices.Equal(got, want) {
+		t.Errorf("history %q, want %q", got, want)
+	}
+	text := g.PGN()
+	if !strings.Contains(text, "\n10... e5 11. Nf3 Nf6 *") {
+		t.Errorf("PGN movetext not numbered from move 10:\n%s", text)
+	}
+	if got, want := g.Board.ToFEN(), "r1bqkb1r/pppp1ppp/2n2n2/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 12"; got != want {
+		t.Errorf("FEN %s, want %s", got, want)
+	}
+
+	board, err := BoardFromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var line []Move
+	for _, notation := range []string{"e7-e5", "g1-f3"} {
+		line = append(line, playMove(t, board, notation))
+	}
+	board.undoMove(line[1])
+	board.undoMove(line[0])
+	if got, want := board.LineSAN(line), "10... e5 11. Nf3"; got != want {
+		t.Errorf("LineSAN %q, want %q", got, want)
+	}
+}
+
+func TestCheckSuffixes(t *testing.T) {
+	t.Cleanup(func() { *doubleCheckPlusFlag = false })
+	tests := []struct {
+		name, fen, move string
+		san, doublePlus string // Without and with -double-check-plus
+	}{
+		{"check", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "a1-a8", "Ra8+", "Ra8+"},
+		// The knight checks and uncovers the rook's check along the e-file
+		{"double check", "4k3/8/8/8/4N3/8/8/4R2K w - - 0 1", "e4-d6", "Nd6+", "Nd6++"},
+		{"mate", "6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1", "a1-a8", "Ra8#", "Ra8#"},
+	}
+	for _, tt := range tests {
+		for _, doublePlus := range []bool{false, true} {
+			*doubleCheckPlusFlag = doublePlus
+			want := tt.san
+			if doublePlus {
+				want = tt.doublePlus
+			}
+			g, err := NewGameFromFEN(tt.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			board := g.Board
+			parsed, err := ParseCoordinateMove(tt.move)
+			if err != nil {
+				t.Fatal(err)
+			}
+			move, err := board.ResolveMove(parsed, White)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if got := board.SAN(move); got != want {
+				t.Errorf("%s: SAN = %s with -double-check-plus %v, want %s", tt.name, got, doublePlus, want)
+			}
+			// PGN always writes a plain "+"
+			if got := board.strictSAN(move); got != tt.san {
+				t.Errorf("%s: strict SAN = %s, want %s", tt.name, got, tt.san)
+			}
+			if err := g.Play(move); err != nil {
+				t.Fatal(err)
+			}
+			if text := g.PGN(); !strings.Contains(text, "1. "+tt.san+" ") {
+				t.Errorf("%s: PGN doesn't contain %s:\n%s", tt.name, tt.san, text)
+			}
+		}
+
+		// Any suffix reads back as the same move
+		for _, suffix := range []string{"", "+", "++", "#"} {
+			board, err := BoardFromFEN(tt.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			san := strings.TrimRight(tt.san, "+#") + suffix
+			move, err := board.ParseSAN(san)
+			if err != nil {
+				t.Errorf("%s: ParseSAN(%q): %v", tt.name, san, err)
+			} else if move.String() != tt.move {
+				t.Errorf("%s: ParseSAN(%q) = %s, want %s", tt.name, san, move, tt.move)
+			}
+		}
+	}
+}
+
+func TestParsePGNWindowsFile(t *testing.T) {
+	text := byteOrderMark + "[Event \"Casual\"]\r\n[White \"Ann\" ]\r\n\r\n1. e4 e5 \r\n\t2. Nf3 {develops} Nc6 1-0\r\n \r\n"
+	games, err := ParsePGN(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 {
+		t.Fatalf("read %d games, want 1", len(games))
+	}
+	g := games[0]
+	if g.Tags["Event"] != "Casual" || g.Tags["White"] != "Ann" {
+		t.Errorf("tags %v", g.Tags)
+	}
+	if want := []string{"e4", "e5", "Nf3", "Nc6"}; !slices.Equal(g.Moves, want) {
+		t.Errorf("moves %v, want %v", g.Moves, want)
+	}
+	if g.Result != "1-0" {
+		t.Errorf("result %q, want 1-0", g.Result)
+	}
+}
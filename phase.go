@@ -0,0 +1,61 @@
+package main
+
+// Phase is the stage of the game a position belongs to
+type Phase int
+
+const (
+	Opening Phase = iota
+	Middlegame
+	Endgame
+)
+
+var phaseNames = map[Phase]string{
+	Opening:    "opening",
+	Middlegame: "middlegame",
+	Endgame:    "endgame",
+}
+
+func (p Phase) String() string {
+	return phaseNames[p]
+}
+
+// Phase thresholds. Material counts minor pieces as 1, rooks as 2 and
+// queens as 4, so the starting position has 24.
+const (
+	// endgameMaterial is the most non-pawn material left in an endgame,
+	// e.g. a rook and a minor piece each, or a queen and a rook against two
+	// minor pieces
+	endgameMaterial = 8
+	// openingUndeveloped is the fewest minor pieces still on their starting
+	// squares, counting both sides, for the position to be an opening
+	openingUndeveloped = 4
+)
+
+var phaseWeights = map[PieceType]int{Knight: 1, Bishop: 1, Rook: 2, Queen: 4}
+
+// Phase classifies the position by material and development: an endgame
+// once little non-pawn material is left, otherwise an opening while most
+// minor pieces are undeveloped, and a middlegame after that
+func (b *Board) Phase() Phase {
+	material, undeveloped := 0, 0
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece == nil {
+				continue
+			}
+			material += phaseWeights[piece.Type]
+			if (piece.Type == Knight || piece.Type == Bishop) && onStartingSquare(piece, Position{row, col}) {
+				undeveloped++
+			}
+		}
+	}
+
+	switch {
+	case material <= endgameMaterial:
+		return Endgame
+	case undeveloped >= openingUndeveloped:
+		return Opening
+	}
+	return Middlegame
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestPhase(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want Phase
+	}{
+		{"starting position", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", Opening},
+		// Both knights out on each side leaves the four bishops at home
+		{"four minor pieces at home", "r1bqkb1r/pppppppp/2n2n2/8/8/2N2N2/PPPPPPPP/R1BQKB1R w KQkq - 4 3", Opening},
+		{"three minor pieces at home", "r1bqkb1r/pppppppp/2n2n2/8/8/2N2N2/PPPPPPPP/R1BQK2R w KQkq - 4 3", Middlegame},
+		// A queen each is exactly the endgame limit
+		{"endgame material", "3qk3/pppppppp/8/8/8/8/PPPPPPPP/3QK3 w - - 0 1", Endgame},
+		{"one minor piece over", "3qk3/pppppppp/8/8/8/5N2/PPPPPPPP/3QK3 w - - 0 1", Middlegame},
+		// Undeveloped pieces don't make an endgame an opening
+		{"endgame with pieces at home", "1n2kb2/pppppppp/8/8/8/8/PPPPPPPP/1N2KB2 w - - 0 1", Endgame},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := board.Phase(); got != tt.want {
+			t.Errorf("%s: Phase() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import "fmt"
+
+// promotionChoices are the pieces a pawn may promote to, strongest first
+var promotionChoices = []PieceType{Queen, Rook, Bishop, Knight}
+
+// ResolveMove validates a move given by its squares and promotion choice,
+// as returned by ParseCoordinateMove, and returns the full move. A
+// promotion of Pawn means the default queen.
+func (b *Board) ResolveMove(m Move, player Player) (Move, error) {
+	move, err := b.CheckMove(m.From, m.To, player)
+	if err != nil {
+		return move, err
+	}
+	if m.Promotion != Pawn {
+		if move.Promotion == Pawn {
+			return move, fmt.Errorf("only a pawn reaching the last rank can promote")
+		}
+		move.Promotion = m.Promotion
+	}
+	move.Comment = m.Comment
+	return move, nil
+}
+
+// requirePromotion rejects a pawn move to the last rank that doesn't name
+// the piece to promote to. Only moves typed by a player default to a queen;
+// moves from scripts, network peers and the JSON protocol must say.
+func (b *Board) requirePromotion(m Move) error {
+	piece := b.squares[m.From.Row][m.From.Col]
+	if m.Promotion != Pawn || piece == nil || piece.Type != Pawn || (m.To.Row != 0 && m.To.Row != 7) {
+		return nil
+	}
+	return fmt.Errorf("%s promotes a pawn, add the piece to promote to, e.g. %sq", m, m)
+}
+
+// Apply makes a move given by its squares and promotion choice for player
+func (b *Board) Apply(m Move, player Player) error {
+	move, err := b.ResolveMove(m, player)
+	if err != nil {
+		return err
+	}
+	b.makeMove(move)
+	b.recordPosition()
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnderpromotionChannels(t *testing.T) {
+	// Each way a move can come in must keep the piece chosen, and reach the
+	// same position as playing it directly
+	fen := "4k3/P7/8/8/8/8/8/4K3 w - - 0 1"
+	channels := []struct {
+		name string
+		read func(t *testing.T, notation string) Move
+	}{
+		{"typed", func(t *testing.T, notation string) Move {
+			h := &HumanSource{scanner: bufio.NewScanner(strings.NewReader(notation + "\n"))}
+			g, err := NewGameFromFEN(fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			move, err := h.NextMove(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return move
+		}},
+		{"script", func(t *testing.T, notation string) Move {
+			path := filepath.Join(t.TempDir(), "moves.txt")
+			if err := os.WriteFile(path, []byte("# underpromotion\n"+notation+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			s, err := NewScriptedSource(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := NewGameFromFEN(fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			move, err := s.NextMove(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return move
+		}},
+		{"PGN", func(t *testing.T, notation string) Move {
+			games, err := ParsePGN(playGame(t, fen, []string{notation}).PGN())
+			if err != nil {
+				t.Fatal(err)
+			}
+			board, err := BoardFromFEN(games[0].Tags["FEN"])
+			if err != nil {
+				t.Fatal(err)
+			}
+			move, err := board.ParseSAN(games[0].Moves[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			return move
+		}},
+		{"encoding", func(t *testing.T, notation string) Move {
+			board, err := BoardFromFEN(fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseCoordinateMove(notation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			move, err := board.ResolveMove(parsed, White)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := DecodeMove(move.Encode(), board)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return decoded
+		}},
+		{"UCI", func(t *testing.T, notation string) Move {
+			parsed, err := ParseCoordinateMove(notation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			uci := UCIMove(parsed)
+			board, err := uciPosition(append(strings.Fields("fen "+fen), "moves", uci))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return board.lastMove
+		}},
+	}
+	for _, notation := range []string{"a7-a8n", "a7-a8b", "a7-a8r"} {
+		want, err := ParseCoordinateMove(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		direct := playGame(t, fen, []string{notation}).Board.ToFEN()
+		for _, channel := range channels {
+			move := channel.read(t, notation)
+			if move.From != want.From || move.To != want.To || move.Promotion != want.Promotion {
+				t.Errorf("%s: %s came through as %s", channel.name, notation, move)
+				continue
+			}
+			board, err := BoardFromFEN(fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := board.Apply(move, White); err != nil {
+				t.Fatalf("%s: %s: %v", channel.name, notation, err)
+			}
+			if got := board.ToFEN(); got != direct {
+				t.Errorf("%s: %s reached %s, want %s", channel.name, notation, got, direct)
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JSON protocol
+//
+// With -protocol=json the program runs as a backend for a separate GUI.
+// Every line on stdin is one JSON request and every request is answered
+// by exactly one JSON response line on stdout.
+//
+// Requests:
+//
+//	{"move": "e2-e4"}                     play a move for the side to move
+//	{"move": "e7-e8n"}                    promote, naming the piece (q, r, b or n)
+//	{"move": "e7-e8", "promotion": "n"}   the same with a separate field
+//	{"cmd": "new"}                        start a new game
+//	{"cmd": "state"}                      report the current position without moving
+//
+// Responses:
+//
+//	{"legal": true, "san": "e4", "fen": "...", "to_move": "Black", "check": false}
+//
+// "legal" is false when the request was rejected, in which case "error"
+// explains why and the position is unchanged. A promotion without a piece
+// is rejected rather than defaulting to a queen. "promotion" is set to the
+// piece a move promoted to. "result" is set to "1-0", "0-1" or "1/2-1/2"
+// once the game is over.
+
+// ProtocolRequest is one line of input in JSON protocol mode
+type ProtocolRequest struct {
+	Cmd       string `json:"cmd,omitempty"`       // "move" (default), "new" or "state"
+	Move      string `json:"move,omitempty"`      // Coordinate notation, e.g. "e2-e4"
+	Promotion string `json:"promotion,omitempty"` // Piece to promote to: "q", "r", "b" or "n"
+}
+
+// ProtocolResponse is one line of output in JSON protocol mode
+type ProtocolResponse struct {
+	Legal     bool   `json:"legal"`
+	Error     string `json:"error,omitempty"`
+	SAN       string `json:"san,omitempty"`
+	Promotion string `json:"promotion,omitempty"`
+	FEN       string `json:"fen"`
+	ToMove    string `json:"to_move"`
+	Check     bool   `json:"check"`
+	Result    string `json:"result,omitempty"`
+}
+
+// RunJSONProtocol serves JSON protocol requests from in until EOF
+func RunJSONProtocol(in io.Reader, out io.Writer) error {
+	board := NewBoard()
+	scanner := bufio.NewScanner(in)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req ProtocolRequest
+		var resp ProtocolResponse
+		if err := json.Unmarshal(line, &req); err != nil {
+			resp.Error = fmt.Sprintf("malformed request: %v", err)
+		} else {
+			switch req.Cmd {
+			case "", "move":
+				resp = protocolMove(board, req.Move, req.Promotion)
+			case "new":
+				board = NewBoard()
+				resp.Legal = true
+			case "state":
+				resp.Legal = true
+			default:
+				resp.Error = fmt.Sprintf("unknown command %q", req.Cmd)
+			}
+		}
+
+		resp.FEN = board.ToFEN()
+		resp.ToMove = board.ToMove().String()
+		resp.Check = board.IsInCheck(board.ToMove())
+		resp.Result = board.Result().Token()
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// protocolMove applies a move in coordinate notation to the board, with
+// the promotion piece given in the move or separately
+func protocolMove(board *Board, notation, promotion string) ProtocolResponse {
+	if board.Result().IsOver() {
+		return ProtocolResponse{Error: "game is over"}
+	}
+
+	parsed, err := ParseCoordinateMove(notation)
+	if err != nil {
+		return ProtocolResponse{Error: err.Error()}
+	}
+	if promotion != "" {
+		pt, ok := pieceTypeFromLetter(strings.ToLower(promotion)[0])
+		if len(promotion) != 1 || !ok || pt == Pawn || pt == King || (parsed.Promotion != Pawn && parsed.Promotion != pt) {
+			return ProtocolResponse{Error: fmt.Sprintf("invalid promotion %q (q, r, b or n)", promotion)}
+		}
+		parsed.Promotion = pt
+	}
+	if err := board.requirePromotion(parsed); err != nil {
+		return ProtocolResponse{Error: err.Error()}
+	}
+
+	// SAN has to be computed before the move is made
+	player := board.ToMove()
+	move, err := board.ResolveMove(parsed, player)
+	if err != nil {
+		return ProtocolResponse{Error: err.Error()}
+	}
+	san := board.strictSAN(move)
+
+	board.makeMove(move)
+	board.recordPosition()
+	resp := ProtocolResponse{Legal: true, SAN: san}
+	if move.Promotion != Pawn {
+		resp.Promotion = string(pieceLetters[move.Promotion])
+	}
+	return resp
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed puzzles.txt
+var embeddedPuzzles string
+
+// Puzzle is a position with a forced winning line for the side to move
+type Puzzle struct {
+	FEN      string
+	Solution []string // Solver's moves and opponent replies, alternating
+	Theme    string
+}
+
+// ParsePuzzles reads puzzles in "FEN;solution;theme" lines, skipping blank
+// lines and '#' comments
+func ParsePuzzles(text string) ([]Puzzle, error) {
+	var puzzles []Puzzle
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("puzzle line %d: expected FEN;solution", i+1)
+		}
+		p := Puzzle{FEN: fields[0], Solution: strings.Fields(fields[1])}
+		if len(fields) > 2 {
+			p.Theme = fields[2]
+		}
+		if len(p.Solution) == 0 {
+			return nil, fmt.Errorf("puzzle line %d: empty solution", i+1)
+		}
+		puzzles = append(puzzles, p)
+	}
+	return puzzles, nil
+}
+
+// RunPuzzles runs a tactics training session over the puzzles, reading
+// answers from scanner, and returns how many were solved
+func RunPuzzles(puzzles []Puzzle, scanner *bufio.Scanner) int {
+	solved := 0
+	for i, p := range puzzles {
+		if *noClearFlag {
+			fmt.Println()
+		} else {
+			ClearScreen()
+		}
+		fmt.Printf("Puzzle %d of %d: %s (score %d/%d)\n\n", i+1, len(puzzles), p.Theme, solved, i)
+
+		ok, quit := solvePuzzle(p, scanner)
+		if ok {
+			solved++
+		}
+		if quit {
+			break
+		}
+	}
+	fmt.Printf("\nSolved %d of %d puzzles.\n", solved, len(puzzles))
+	return solved
+}
+
+// solvePuzzle lets the player work through one puzzle, auto-playing the
+// opponent's replies. Wrong moves can be retried; asking for the solution
+// gives up the puzzle.
+func solvePuzzle(p Puzzle, scanner *bufio.Scanner) (solved, quit bool) {
+	board, err := BoardFromFEN(p.FEN)
+	if err != nil {
+		fmt.Printf("Error: invalid puzzle: %v\n", err)
+		return false, false
+	}
+	solver := board.ToMove()
+
+	for step := 0; step < len(p.Solution); step += 2 {
+		expected, err := puzzleMove(board, p.Solution[step])
+		if err != nil {
+			fmt.Printf("Error: invalid puzzle solution: %v\n", err)
+			return false, false
+		}
+
+		board.Draw()
+		for {
+			fmt.Printf("\n%s to move. Your move ('solution', 'skip' or 'quit'): ", solver)
+			if !scanner.Scan() {
+				return false, true
+			}
+			input := strings.TrimSpace(scanner.Text())
+
+			switch input {
+			case "quit":
+				return false, true
+			case "skip":
+				return false, false
+			case "solution":
+				fmt.Printf("Solution: %s\n", puzzleLineSAN(board, p.Solution[step:]))
+				fmt.Println("Press Enter to continue...")
+				scanner.Scan()
+				return false, false
+			}
+
+			parsed, err := ParseCoordinateMove(input)
+			var move Move
+			if err == nil {
+				move, err = board.ResolveMove(parsed, solver)
+			}
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+
+			// Any move that mates on the last step is as good as the intended one
+			last := step == len(p.Solution)-1
+			if sameMove(move, expected) || last && board.matesWith(move) {
+				board.makeMove(move)
+				board.recordPosition()
+				break
+			}
+			fmt.Println("Incorrect, try again.")
+		}
+
+		fmt.Println("Correct!")
+		if step+1 < len(p.Solution) {
+			reply, err := puzzleMove(board, p.Solution[step+1])
+			if err != nil {
+				fmt.Printf("Error: invalid puzzle solution: %v\n", err)
+				return false, false
+			}
+			fmt.Printf("Opponent plays %s\n\n", board.SAN(reply))
+			board.makeMove(reply)
+			board.recordPosition()
+		}
+	}
+
+	fmt.Println("\nPuzzle solved! Press Enter to continue...")
+	scanner.Scan()
+	return true, false
+}
+
+// puzzleMove parses and validates a move of a puzzle solution
+func puzzleMove(board *Board, notation string) (Move, error) {
+	parsed, err := ParseCoordinateMove(notation)
+	if err != nil {
+		return Move{}, fmt.Errorf("%s: %v", notation, err)
+	}
+	move, err := board.ResolveMove(parsed, board.ToMove())
+	if err != nil {
+		return Move{}, fmt.Errorf("%s: %v", notation, err)
+	}
+	return move, nil
+}
+
+// puzzleLineSAN formats the remaining solution line in SAN. The moves are
+// resolved on a snapshot and formatted there too, since they refer to the
+// snapshot's pieces; the board passed in is left alone.
+func puzzleLineSAN(board *Board, line []string) string {
+	b := board.Snapshot()
+	var moves []Move
+	for _, notation := range line {
+		move, err := puzzleMove(b, notation)
+		if err != nil {
+			break
+		}
+		moves = append(moves, move)
+		b.makeMove(move)
+	}
+	for i := len(moves) - 1; i >= 0; i-- {
+		b.undoMove(moves[i])
+	}
+	return b.LineSAN(moves)
+}
+
+// matesWith reports whether the move, already validated, checkmates the
+// opponent. The board is left unchanged.
+func (b *Board) matesWith(move Move) bool {
+	opponent := 1 - move.Piece.Player
+	b.makeMove(move)
+	mate := b.IsCheckmate(opponent)
+	b.undoMove(move)
+	return mate
+}
+
+// sameMove reports whether two moves have the same squares and promotion
+func sameMove(a, b Move) bool {
+	return a.From == b.From && a.To == b.To && a.Promotion == b.Promotion
+}
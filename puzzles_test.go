@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestPuzzleLineSAN(t *testing.T) {
+	tests := []struct {
+		fen  string
+		line []string
+		want string
+	}{
+		{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", []string{"a1-a8"}, "1. Ra8#"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", []string{"h5-f7"}, "4. Qxf7#"},
+		{"r3k3/8/8/1N6/8/8/8/4K3 w - - 0 1", []string{"b5-c7", "e8-d8", "c7-a8"}, "1. Nc7+ Kd8 2. Nxa8"},
+		{"r3k3/8/8/1N6/8/8/8/4K3 w - - 0 1", []string{"b5-c7", "e8-d8", "c7-a1"}, "1. Nc7+ Kd8"},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fen, err)
+		}
+		if got := puzzleLineSAN(board, tt.line); got != tt.want {
+			t.Errorf("puzzleLineSAN(%s, %v) = %q, want %q", tt.fen, tt.line, got, tt.want)
+		}
+		if got := board.ToFEN(); got != tt.fen {
+			t.Errorf("puzzleLineSAN changed the board to %s", got)
+		}
+	}
+}
+
+func TestEmbeddedPuzzlesSolve(t *testing.T) {
+	puzzles, err := ParsePuzzles(embeddedPuzzles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range puzzles {
+		board, err := BoardFromFEN(p.FEN)
+		if err != nil {
+			t.Errorf("%s: %v", p.Theme, err)
+			continue
+		}
+		for _, notation := range p.Solution {
+			move, err := puzzleMove(board, notation)
+			if err != nil {
+				t.Errorf("%s: %v", p.Theme, err)
+				break
+			}
+			board.makeMove(move)
+		}
+	}
+}
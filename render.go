@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// BorderStyle holds the glyphs used for the frame around the board
+type BorderStyle struct {
+	Horizontal string
+	Vertical   string
+}
+
+var borderStyles = map[string]BorderStyle{
+	"unicode": {Horizontal: "─", Vertical: "│"},
+	"ascii":   {Horizontal: "-", Vertical: "|"},
+	"none":    {Horizontal: " ", Vertical: " "},
+}
+
+// RenderOptions controls how the board is drawn
+type RenderOptions struct {
+	Border BorderStyle
+	// Dotted gridlines between squares and ranks, and the file letters on
+	// both sides of every rank line, e.g. "a8│ ... │h8"
+	Guides bool
+	// Draw from Black's side, with rank 1 at the top. Flipping only
+	// changes the drawing: squares typed by the player are always absolute,
+	// so e2 is e2 whichever way up the board is shown.
+	Flipped bool
+	// Squares drawn as '*' to point them out, e.g. in the coordinate trainer
+	Highlight []Position
+	// The move that led to the position, noted as "← e2-e4" beside the rank
+	// it ended on. With Color, its from square is also dimmed and its to
+	// square drawn bright.
+	LastMove *Move
+	Color    bool // Use ANSI terminal colors
+	// Name each empty square, e.g. "e4", to help find squares. Squares are
+	// drawn two characters wide to make room; with Color the names are dim.
+	Labels bool
+	// Mark the edge of the board on the side to move's side with, e.g.,
+	// "◀ White to move"; with Color the marker is bright
+	ShowTurn bool
+	// With Color, dim every square but these, e.g. to leave only a selected
+	// piece and its destinations standing out
+	Focus []Position
+}
+
+// ANSI escape sequences for the last move's squares
+const (
+	ansiDim    = "\033[2m"
+	ansiBright = "\033[1;33m"
+	ansiReset  = "\033[0m"
+)
+
+// renderOptions are the options used by Draw, set from the command line
+var renderOptions = RenderOptions{Border: borderStyles["unicode"]}
+
+// DrawString renders the board as text
+func (b *Board) DrawString(opts RenderOptions) string {
+	var sb strings.Builder
+	// Squares are one character wide, or two to fit labels
+	width := 1
+	if opts.Labels {
+		width = 2
+	}
+	cell := func(s string) string {
+		return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+	}
+	// The rank numbers to the sides of the board, with guides also the
+	// file letters, take up the margin
+	margin := " "
+	if opts.Guides {
+		margin = "  "
+	}
+	frame := margin + " " + strings.Repeat(opts.Border.Horizontal, 8*width+9)
+	topFrame, bottomFrame := frame+"\n", frame+"\n"
+	if opts.ShowTurn {
+		turn := fmt.Sprintf(" ◀ %s to move", b.ToMove())
+		if opts.Color {
+			turn = ansiBright + turn + ansiReset
+		}
+		// White plays up the board unless it is flipped
+		if (b.ToMove() == White) != opts.Flipped {
+			bottomFrame = frame + turn + "\n"
+		} else {
+			topFrame = frame + turn + "\n"
+		}
+	}
+
+	// Rows and columns in drawing order
+	order := [8]int{0, 1, 2, 3, 4, 5, 6, 7}
+	if opts.Flipped {
+		order = [8]int{7, 6, 5, 4, 3, 2, 1, 0}
+	}
+	files := make([]string, 8)
+	for i, col := range order {
+		files[i] = cell(string(rune('a' + col)))
+	}
+
+	header := strings.TrimRight(margin+"  "+strings.Join(files, " "), " ") + "\n"
+	sb.WriteString(header)
+	sb.WriteString(topFrame)
+	// Guides replace the spaces between squares, keeping the board's width
+	sep := " "
+	gridline := ""
+	if opts.Guides {
+		sep = ":"
+		dots := strings.TrimRight(strings.Repeat(cell("·")+sep, 8), sep)
+		gridline = fmt.Sprintf("%s%s %s %s\n", margin, opts.Border.Vertical, dots, opts.Border.Vertical)
+	}
+	for i, row := range order {
+		left, right := strconv.Itoa(8-row), strconv.Itoa(8-row)
+		if opts.Guides {
+			if i > 0 {
+				sb.WriteString(gridline)
+			}
+			left = string(rune('a'+order[0])) + left
+			right = string(rune('a'+order[7])) + right
+		}
+		fmt.Fprintf(&sb, "%s%s ", left, opts.Border.Vertical)
+		for j, col := range order {
+			pos := Position{row, col}
+			square := cell(".")
+			if slices.Contains(opts.Highlight, pos) {
+				square = cell("*")
+			} else if b.squares[row][col] != nil {
+				square = cell(b.squares[row][col].String())
+			} else if opts.Labels && opts.Color {
+				square = ansiDim + pos.String() + ansiReset
+			} else if opts.Labels {
+				square = pos.String()
+			}
+			if opts.Color && opts.Focus != nil && !slices.Contains(opts.Focus, pos) {
+				square = ansiDim + square + ansiReset
+			} else if opts.Color && opts.LastMove != nil {
+				switch pos {
+				case opts.LastMove.From:
+					square = ansiDim + square + ansiReset
+				case opts.LastMove.To:
+					square = ansiBright + square + ansiReset
+				}
+			}
+			sb.WriteString(square)
+			if j < 7 {
+				sb.WriteString(sep)
+			}
+		}
+		fmt.Fprintf(&sb, " %s%s", opts.Border.Vertical, right)
+		if opts.LastMove != nil && opts.LastMove.To.Row == row {
+			fmt.Fprintf(&sb, " ← %s", opts.LastMove)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(bottomFrame)
+	sb.WriteString(header)
+
+	return sb.String()
+}
+
+// columnGap is the space between the board and the panel beside it
+const columnGap = 4
+
+// terminalWidth returns the terminal width from $COLUMNS, or false if it
+// isn't set. Shells keep COLUMNS unexported by default, so it has to be
+// exported (or set on the command line) to enable the wide layout.
+func terminalWidth() (int, bool) {
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || width <= 0 {
+		return 0, false
+	}
+	return width, true
+}
+
+// besideBoard lays out the panel lines to the right of the board. It
+// returns false if the two don't fit side by side in width columns.
+func besideBoard(board string, panel []string, width int) (string, bool) {
+	rows := strings.Split(strings.TrimSuffix(board, "\n"), "\n")
+	boardWidth := 0
+	for _, row := range rows {
+		boardWidth = max(boardWidth, utf8.RuneCountInString(row))
+	}
+	panelWidth := 0
+	for _, line := range panel {
+		panelWidth = max(panelWidth, utf8.RuneCountInString(line))
+	}
+	if boardWidth+columnGap+panelWidth > width {
+		return "", false
+	}
+
+	var sb strings.Builder
+	for i := 0; i < max(len(rows), len(panel)); i++ {
+		left, right := "", ""
+		if i < len(rows) {
+			left = rows[i]
+		}
+		if i < len(panel) {
+			right = panel[i]
+		}
+		if right == "" {
+			sb.WriteString(left + "\n")
+			continue
+		}
+		pad := boardWidth + columnGap - utf8.RuneCountInString(left)
+		sb.WriteString(left + strings.Repeat(" ", pad) + right + "\n")
+	}
+	return sb.String(), true
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// drawTest is a position and the options to draw it with, and the
+// drawing expected line by line
+type drawTest struct {
+	name string
+	fen  string
+	opts RenderOptions
+	want []string
+}
+
+func checkDrawings(t *testing.T, tests []drawTest) {
+	t.Helper()
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := board.DrawString(tt.opts)
+		if want := strings.Join(tt.want, "\n") + "\n"; got != want {
+			t.Errorf("%s: drew\n%s\nwant\n%s", tt.name, got, want)
+		}
+	}
+}
+
+func TestDrawBorders(t *testing.T) {
+	fen := "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"
+	checkDrawings(t, []drawTest{
+		{"unicode", fen, RenderOptions{Border: borderStyles["unicode"]}, []string{
+			"   a b c d e f g h",
+			"  ─────────────────",
+			"8│ . . . . ♚ . . . │8",
+			"7│ . . . . . . . . │7",
+			"6│ . . . . . . . . │6",
+			"5│ . . . . . . . . │5",
+			"4│ . . . . . . . . │4",
+			"3│ . . . . . . . . │3",
+			"2│ . . . . ♙ . . . │2",
+			"1│ . . . . ♔ . . . │1",
+			"  ─────────────────",
+			"   a b c d e f g h",
+		}},
+		{"ascii", fen, RenderOptions{Border: borderStyles["ascii"]}, []string{
+			"   a b c d e f g h",
+			"  -----------------",
+			"8| . . . . ♚ . . . |8",
+			"7| . . . . . . . . |7",
+			"6| . . . . . . . . |6",
+			"5| . . . . . . . . |5",
+			"4| . . . . . . . . |4",
+			"3| . . . . . . . . |3",
+			"2| . . . . ♙ . . . |2",
+			"1| . . . . ♔ . . . |1",
+			"  -----------------",
+			"   a b c d e f g h",
+		}},
+		{"none", fen, RenderOptions{Border: borderStyles["none"]}, []string{
+			"   a b c d e f g h",
+			"                   ",
+			"8  . . . . ♚ . . .  8",
+			"7  . . . . . . . .  7",
+			"6  . . . . . . . .  6",
+			"5  . . . . . . . .  5",
+			"4  . . . . . . . .  4",
+			"3  . . . . . . . .  3",
+			"2  . . . . ♙ . . .  2",
+			"1  . . . . ♔ . . .  1",
+			"                   ",
+			"   a b c d e f g h",
+		}},
+	})
+}
+
+func TestDrawGuides(t *testing.T) {
+	fen := "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"
+	checkDrawings(t, []drawTest{
+		{"guides", fen, RenderOptions{Border: borderStyles["unicode"], Guides: true}, []string{
+			"    a b c d e f g h",
+			"   ─────────────────",
+			"a8│ .:.:.:.:♚:.:.:. │h8",
+			"  │ ·:·:·:·:·:·:·:· │",
+			"a7│ .:.:.:.:.:.:.:. │h7",
+			"  │ ·:·:·:·:·:·:·:· │",
+			"a6│ .:.:.:.:.:.:.:. │h6",
+			"  │ ·:·:·:·:·:·:·:· │",
+			"a5│ .:.:.:.:.:.:.:. │h5",
+			"  │ ·:·:·:·:·:·:·:· │",
+			"a4│ .:.:.:.:.:.:.:. │h4",
+			"  │ ·:·:·:·:·:·:·:· │",
+			"a3│ .:.:.:.:.:.:.:. │h3",
+			"  │ ·:·:·:·:·:·:·:· │",
+			"a2│ .:.:.:.:♙:.:.:. │h2",
+			"  │ ·:·:·:·:·:·:·:· │",
+			"a1│ .:.:.:.:♔:.:.:. │h1",
+			"   ─────────────────",
+			"    a b c d e f g h",
+		}},
+		{"guides flipped", fen, RenderOptions{Border: borderStyles["ascii"], Guides: true, Flipped: true}, []string{
+			"    h g f e d c b a",
+			"   -----------------",
+			"h1| .:.:.:♔:.:.:.:. |a1",
+			"  | ·:·:·:·:·:·:·:· |",
+			"h2| .:.:.:♙:.:.:.:. |a2",
+			"  | ·:·:·:·:·:·:·:· |",
+			"h3| .:.:.:.:.:.:.:. |a3",
+			"  | ·:·:·:·:·:·:·:· |",
+			"h4| .:.:.:.:.:.:.:. |a4",
+			"  | ·:·:·:·:·:·:·:· |",
+			"h5| .:.:.:.:.:.:.:. |a5",
+			"  | ·:·:·:·:·:·:·:· |",
+			"h6| .:.:.:.:.:.:.:. |a6",
+			"  | ·:·:·:·:·:·:·:· |",
+			"h7| .:.:.:.:.:.:.:. |a7",
+			"  | ·:·:·:·:·:·:·:· |",
+			"h8| .:.:.:♚:.:.:.:. |a8",
+			"   -----------------",
+			"    h g f e d c b a",
+		}},
+	})
+}
+
+func TestDrawLastMove(t *testing.T) {
+	tests := []struct {
+		name, fen, move string
+		color           bool
+		want            []string
+	}{
+		{"move", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", "e2-e4", false, []string{
+			"   a b c d e f g h",
+			"  ─────────────────",
+			"8│ . . . . ♚ . . . │8",
+			"7│ . . . . . . . . │7",
+			"6│ . . . . . . . . │6",
+			"5│ . . . . . . . . │5",
+			"4│ . . . . ♙ . . . │4 ← e2-e4",
+			"3│ . . . . . . . . │3",
+			"2│ . . . . . . . . │2",
+			"1│ . . . . ♔ . . . │1",
+			"  ─────────────────",
+			"   a b c d e f g h",
+		}},
+		{"capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4-d5", false, []string{
+			"   a b c d e f g h",
+			"  ─────────────────",
+			"8│ . . . . ♚ . . . │8",
+			"7│ . . . . . . . . │7",
+			"6│ . . . . . . . . │6",
+			"5│ . . . ♙ . . . . │5 ← e4-d5",
+			"4│ . . . . . . . . │4",
+			"3│ . . . . . . . . │3",
+			"2│ . . . . . . . . │2",
+			"1│ . . . . ♔ . . . │1",
+			"  ─────────────────",
+			"   a b c d e f g h",
+		}},
+		{"castle", "4k3/8/8/8/8/8/8/4K2R w K - 0 1", "e1-g1", false, []string{
+			"   a b c d e f g h",
+			"  ─────────────────",
+			"8│ . . . . ♚ . . . │8",
+			"7│ . . . . . . . . │7",
+			"6│ . . . . . . . . │6",
+			"5│ . . . . . . . . │5",
+			"4│ . . . . . . . . │4",
+			"3│ . . . . . . . . │3",
+			"2│ . . . . . . . . │2",
+			"1│ . . . . . ♖ ♔ . │1 ← e1-g1",
+			"  ─────────────────",
+			"   a b c d e f g h",
+		}},
+		// With color the from square is dimmed and the to square bright
+		{"capture in color", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4-d5", true, []string{
+			"   a b c d e f g h",
+			"  ─────────────────",
+			"8│ . . . . ♚ . . . │8",
+			"7│ . . . . . . . . │7",
+			"6│ . . . . . . . . │6",
+			"5│ . . . " + ansiBright + "♙" + ansiReset + " . . . . │5 ← e4-d5",
+			"4│ . . . . " + ansiDim + "." + ansiReset + " . . . │4",
+			"3│ . . . . . . . . │3",
+			"2│ . . . . . . . . │2",
+			"1│ . . . . ♔ . . . │1",
+			"  ─────────────────",
+			"   a b c d e f g h",
+		}},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		move := playMove(t, board, tt.move)
+		got := board.DrawString(RenderOptions{Border: borderStyles["unicode"], LastMove: &move, Color: tt.color})
+		if want := strings.Join(tt.want, "\n") + "\n"; got != want {
+			t.Errorf("%s: drew\n%s\nwant\n%s", tt.name, got, want)
+		}
+	}
+}
+
+func TestDrawLabels(t *testing.T) {
+	fen := "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"
+	checkDrawings(t, []drawTest{
+		{"labels", fen, RenderOptions{Border: borderStyles["unicode"], Labels: true}, []string{
+			"   a  b  c  d  e  f  g  h",
+			"  ─────────────────────────",
+			"8│ a8 b8 c8 d8 ♚  f8 g8 h8 │8",
+			"7│ a7 b7 c7 d7 e7 f7 g7 h7 │7",
+			"6│ a6 b6 c6 d6 e6 f6 g6 h6 │6",
+			"5│ a5 b5 c5 d5 e5 f5 g5 h5 │5",
+			"4│ a4 b4 c4 d4 e4 f4 g4 h4 │4",
+			"3│ a3 b3 c3 d3 e3 f3 g3 h3 │3",
+			"2│ a2 b2 c2 d2 ♙  f2 g2 h2 │2",
+			"1│ a1 b1 c1 d1 ♔  f1 g1 h1 │1",
+			"  ─────────────────────────",
+			"   a  b  c  d  e  f  g  h",
+		}},
+		{"no labels flipped", fen, RenderOptions{Border: borderStyles["unicode"], Flipped: true}, []string{
+			"   h g f e d c b a",
+			"  ─────────────────",
+			"1│ . . . ♔ . . . . │1",
+			"2│ . . . ♙ . . . . │2",
+			"3│ . . . . . . . . │3",
+			"4│ . . . . . . . . │4",
+			"5│ . . . . . . . . │5",
+			"6│ . . . . . . . . │6",
+			"7│ . . . . . . . . │7",
+			"8│ . . . ♚ . . . . │8",
+			"  ─────────────────",
+			"   h g f e d c b a",
+		}},
+		// Labels name the squares as typed, whichever way up the board is
+		{"labels flipped", fen, RenderOptions{Border: borderStyles["unicode"], Labels: true, Flipped: true}, []string{
+			"   h  g  f  e  d  c  b  a",
+			"  ─────────────────────────",
+			"1│ h1 g1 f1 ♔  d1 c1 b1 a1 │1",
+			"2│ h2 g2 f2 ♙  d2 c2 b2 a2 │2",
+			"3│ h3 g3 f3 e3 d3 c3 b3 a3 │3",
+			"4│ h4 g4 f4 e4 d4 c4 b4 a4 │4",
+			"5│ h5 g5 f5 e5 d5 c5 b5 a5 │5",
+			"6│ h6 g6 f6 e6 d6 c6 b6 a6 │6",
+			"7│ h7 g7 f7 e7 d7 c7 b7 a7 │7",
+			"8│ h8 g8 f8 ♚  d8 c8 b8 a8 │8",
+			"  ─────────────────────────",
+			"   h  g  f  e  d  c  b  a",
+		}},
+	})
+}
+
+func TestDrawShowTurn(t *testing.T) {
+	opts := RenderOptions{Border: borderStyles["unicode"], ShowTurn: true}
+	flipped := opts
+	flipped.Flipped = true
+	checkDrawings(t, []drawTest{
+		{"white to move", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", opts, []string{
+			"   a b c d e f g h",
+			"  ─────────────────",
+			"8│ . . . . ♚ . . . │8",
+			"7│ . . . . . . . . │7",
+			"6│ . . . . . . . . │6",
+			"5│ . . . . . . . . │5",
+			"4│ . . . . . . . . │4",
+			"3│ . . . . . . . . │3",
+			"2│ . . . . ♙ . . . │2",
+			"1│ . . . . ♔ . . . │1",
+			"  ───────────────── ◀ White to move",
+			"   a b c d e f g h",
+		}},
+		{"black to move", "4k3/8/8/8/4P3/8/8/4K3 b - - 0 1", opts, []string{
+			"   a b c d e f g h",
+			"  ───────────────── ◀ Black to move",
+			"8│ . . . . ♚ . . . │8",
+			"7│ . . . . . . . . │7",
+			"6│ . . . . . . . . │6",
+			"5│ . . . . . . . . │5",
+			"4│ . . . . ♙ . . . │4",
+			"3│ . . . . . . . . │3",
+			"2│ . . . . . . . . │2",
+			"1│ . . . . ♔ . . . │1",
+			"  ─────────────────",
+			"   a b c d e f g h",
+		}},
+		// Flipped, the side to move is still marked on its own edge
+		{"black to move flipped", "4k3/8/8/8/4P3/8/8/4K3 b - - 0 1", flipped, []string{
+			"   h g f e d c b a",
+			"  ─────────────────",
+			"1│ . . . ♔ . . . . │1",
+			"2│ . . . . . . . . │2",
+			"3│ . . . . . . . . │3",
+			"4│ . . . ♙ . . . . │4",
+			"5│ . . . . . . . . │5",
+			"6│ . . . . . . . . │6",
+			"7│ . . . . . . . . │7",
+			"8│ . . . ♚ . . . . │8",
+			"  ───────────────── ◀ Black to move",
+			"   h g f e d c b a",
+		}},
+	})
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MoveNode is a position in a move tree, reached by playing Move from its
+// parent. The first child continues the line; other children are variations.
+type MoveNode struct {
+	Move     Move
+	Parent   *MoveNode
+	Children []*MoveNode
+}
+
+// MoveTree holds a game's mainline together with variations explored
+// during replay. The game itself is never modified, so its PGN only ever
+// contains the mainline.
+type MoveTree struct {
+	Start string // FEN of the starting position
+	Root  *MoveNode
+}
+
+// NewMoveTree creates a tree whose mainline is the game's moves
+func NewMoveTree(g *Game) *MoveTree {
+	t := &MoveTree{Start: g.Start, Root: &MoveNode{}}
+	node := t.Root
+	for _, move := range g.Moves {
+		node = node.AddChild(move)
+	}
+	return t
+}
+
+// AddChild returns the child reached by move, adding it as a new line if
+// it hasn't been played from this node before
+func (n *MoveNode) AddChild(move Move) *MoveNode {
+	for _, child := range n.Children {
+		if sameMove(child.Move, move) {
+			return child
+		}
+	}
+	child := &MoveNode{Move: move, Parent: n}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+// Path returns the moves leading from the root to the node
+func (n *MoveNode) Path() []Move {
+	var moves []Move
+	for ; n.Parent != nil; n = n.Parent {
+		moves = append([]Move{n.Move}, moves...)
+	}
+	return moves
+}
+
+// Ply returns the number of moves played to reach the node
+func (n *MoveNode) Ply() int {
+	ply := 0
+	for ; n.Parent != nil; n = n.Parent {
+		ply++
+	}
+	return ply
+}
+
+// BranchPoint returns the deepest node on the mainline leading to n, which
+// is n itself when it is on the mainline
+func (n *MoveNode) BranchPoint() *MoveNode {
+	branch := n
+	for node := n; node.Parent != nil; node = node.Parent {
+		if node.Parent.Children[0] != node {
+			branch = node.Parent
+		}
+	}
+	return branch
+}
+
+// Board reconstructs the position at the node by replaying its moves from
+// the starting position, and returns it with the line played in SAN
+func (t *MoveTree) Board(n *MoveNode) (*Board, string, error) {
+	board, err := BoardFromFEN(t.Start)
+	if err != nil {
+		return nil, "", err
+	}
+	var line []string
+	for i, m := range n.Path() {
+		// Moves are rebuilt on the new board; those from the game refer to its pieces
+		move, err := board.ResolveMove(m, board.ToMove())
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %v", m, err)
+		}
+		san := board.SAN(move)
+		if board.ToMove() == White {
+			san = fmt.Sprintf("%d. %s", board.moveCount/2+1, san)
+		} else if i == 0 {
+			san = fmt.Sprintf("%d... %s", board.moveCount/2+1, san)
+		}
+		line = append(line, san)
+		board.makeMove(move)
+		board.recordPosition()
+	}
+	return board, strings.Join(line, " "), nil
+}
+
+// replay steps through the game's moves and lets the player explore
+// variations without touching the game itself
+func (h *HumanSource) replay(g *Game) {
+	tree := NewMoveTree(g)
+	node := tree.Root
+	for len(node.Children) > 0 {
+		node = node.Children[0]
+	}
+
+	for {
+		board, line, err := tree.Board(node)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		fmt.Println()
+		opts := renderOptions
+		if node.Parent != nil {
+			opts.LastMove = &node.Move
+		}
+		fmt.Print(board.DrawString(opts))
+		where := "mainline"
+		if node.BranchPoint() != node {
+			where = "variation"
+		}
+		fmt.Printf("\n[replay] ply %d (%s): %s\n", node.Ply(), where, line)
+		fmt.Print("'n'ext, 'b'ack, 'var <move>', 'mainline' or 'end': ")
+		if !h.scanner.Scan() {
+			return
+		}
+
+		fields := strings.Fields(h.scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "n", "next":
+			if len(node.Children) == 0 {
+				fmt.Println("Error: end of line")
+				h.pause()
+				continue
+			}
+			node = node.Children[0]
+		case "b", "back":
+			if node.Parent == nil {
+				fmt.Println("Error: already at the start")
+				h.pause()
+				continue
+			}
+			node = node.Parent
+		case "var":
+			if len(fields) != 2 {
+				fmt.Println("Error: usage: var <move>")
+				h.pause()
+				continue
+			}
+			move, err := ParseCoordinateMove(fields[1])
+			if err == nil {
+				move, err = board.ResolveMove(move, board.ToMove())
+			}
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				h.pause()
+				continue
+			}
+			node = node.AddChild(move)
+		case "mainline":
+			node = node.BranchPoint()
+		case "end":
+			return
+		default:
+			fmt.Printf("Error: unknown replay command %q\n", fields[0])
+			h.pause()
+		}
+	}
+}
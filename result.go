@@ -0,0 +1,164 @@
+package main
+
+import "fmt"
+
+// ResultReason is the rule that ended a game
+type ResultReason int
+
+const (
+	InProgress ResultReason = iota
+	Checkmate
+	Resignation
+	Stalemate
+	ThreefoldRepetition
+	FiftyMoveRule
+	InsufficientMaterial
+	Agreement
+	FivefoldRepetition
+	SeventyFiveMoveRule
+)
+
+var resultReasonNames = map[ResultReason]string{
+	InProgress:           "in progress",
+	Checkmate:            "checkmate",
+	Resignation:          "resignation",
+	Stalemate:            "stalemate",
+	ThreefoldRepetition:  "threefold repetition",
+	FiftyMoveRule:        "fifty-move rule",
+	InsufficientMaterial: "insufficient material",
+	Agreement:            "agreement",
+	FivefoldRepetition:   "fivefold repetition",
+	SeventyFiveMoveRule:  "seventy-five-move rule",
+}
+
+func (r ResultReason) String() string {
+	return resultReasonNames[r]
+}
+
+// drawExplanations say why each drawing rule applied
+var drawExplanations = map[ResultReason]string{
+	Stalemate:            "the side to move has no legal move but is not in check",
+	ThreefoldRepetition:  "the same position occurred three times",
+	FiftyMoveRule:        "fifty moves by each side without a capture or pawn move",
+	InsufficientMaterial: "neither side has enough material to checkmate",
+	Agreement:            "both players agreed",
+	FivefoldRepetition:   "the same position occurred five times",
+	SeventyFiveMoveRule:  "seventy-five moves by each side without a capture or pawn move",
+}
+
+// GameResult is how a game ended: the deciding rule and, for decisive
+// results, the winner
+type GameResult struct {
+	Reason ResultReason
+	Winner Player
+}
+
+// IsOver reports whether the game has ended
+func (r GameResult) IsOver() bool {
+	return r.Reason != InProgress
+}
+
+// IsDraw reports whether the game ended in a draw
+func (r GameResult) IsDraw() bool {
+	return r.IsOver() && r.Reason != Checkmate && r.Reason != Resignation
+}
+
+// Token returns the PGN result token, or "" while the game is in progress
+func (r GameResult) Token() string {
+	switch {
+	case !r.IsOver():
+		return ""
+	case r.IsDraw():
+		return "1/2-1/2"
+	case r.Winner == White:
+		return "1-0"
+	}
+	return "0-1"
+}
+
+// Message describes how the game ended, or returns "" while it is in
+// progress. Draws name the rule that applied and why.
+func (r GameResult) Message() string {
+	switch r.Reason {
+	case InProgress:
+		return ""
+	case Checkmate:
+		return fmt.Sprintf("Checkmate! %s wins!", r.Winner)
+	case Resignation:
+		return fmt.Sprintf("%s resigns. %s wins!", 1-r.Winner, r.Winner)
+	case Stalemate:
+		return fmt.Sprintf("Stalemate! The game is a draw: %s.", drawExplanations[r.Reason])
+	}
+	return fmt.Sprintf("Draw by %s: %s.", r.Reason, drawExplanations[r.Reason])
+}
+
+// Result returns how the position ends the game without any claim:
+// checkmate, stalemate, insufficient material, or the seventy-five-move and
+// fivefold repetition rules. Checkmate on the last move takes precedence
+// over the automatic draws.
+func (b *Board) Result() GameResult {
+	player := b.ToMove()
+	switch {
+	case b.IsCheckmate(player):
+		return GameResult{Reason: Checkmate, Winner: 1 - player}
+	case b.IsStalemate(player):
+		return GameResult{Reason: Stalemate}
+	case b.IsInsufficientMaterial():
+		return GameResult{Reason: InsufficientMaterial}
+	case b.Rules().FiftyMove && b.halfMoveClock >= 150:
+		return GameResult{Reason: SeventyFiveMoveRule}
+	case b.Rules().Threefold && b.RepetitionCount() >= 5:
+		return GameResult{Reason: FivefoldRepetition}
+	}
+	return GameResult{}
+}
+
+// ClaimableDraw returns the rule under which the side to move may claim a
+// draw (threefold repetition or the fifty-move rule), or InProgress if
+// neither applies
+func (b *Board) ClaimableDraw() ResultReason {
+	switch {
+	case b.Rules().Threefold && b.RepetitionCount() >= 3:
+		return ThreefoldRepetition
+	case b.Rules().FiftyMove && b.halfMoveClock >= 100:
+		return FiftyMoveRule
+	}
+	return InProgress
+}
+
+// ClaimableDrawAfter returns the rule under which the side to move may
+// claim a draw by announcing move without playing it: the move would repeat
+// a position for the third time or complete fifty moves without a capture
+// or pawn move. It returns InProgress if neither applies. The board is left
+// unchanged.
+func (b *Board) ClaimableDrawAfter(move Move) ResultReason {
+	rules := b.Rules()
+	b.makeMove(move)
+	defer b.undoMove(move)
+	switch {
+	case rules.Threefold && b.RepetitionCount()+1 >= 3:
+		return ThreefoldRepetition
+	case rules.FiftyMove && b.halfMoveClock >= 100:
+		return FiftyMoveRule
+	}
+	return InProgress
+}
+
+// DrawClaimMoves returns the legal moves with which the side to move could
+// claim a draw under ClaimableDrawAfter
+func (b *Board) DrawClaimMoves() []Move {
+	var moves []Move
+	for _, move := range b.LegalMoves(b.ToMove()) {
+		if b.ClaimableDrawAfter(move) != InProgress {
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// CanClaimDraw reports whether the side to move may claim a draw, either in
+// the current position or, as the FIDE rules allow, by announcing a move
+// that would bring about the third repetition or the fiftieth move
+func (b *Board) CanClaimDraw() bool {
+	return b.ClaimableDraw() != InProgress || len(b.DrawClaimMoves()) > 0
+}
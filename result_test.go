@@ -0,0 +1,159 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// playUntil applies moves in coordinate notation, recording each position
+// as a game does, and returns the ply after which Result first reported a
+// finished game, or 0 if it never did
+func playUntil(t *testing.T, b *Board, moves []string) (int, GameResult) {
+	t.Helper()
+	for i, notation := range moves {
+		m, err := ParseCoordinateMove(notation)
+		if err != nil {
+			t.Fatalf("%s: %v", notation, err)
+		}
+		if err := b.Apply(m, b.ToMove()); err != nil {
+			t.Fatalf("%s: %s: %v", b.ToFEN(), notation, err)
+		}
+		if result := b.Result(); result.Reason != InProgress {
+			return i + 1, result
+		}
+	}
+	return 0, GameResult{}
+}
+
+func TestSeventyFiveMoveRule(t *testing.T) {
+	shuffle := []string{"a1-a2", "e8-d8", "a2-a1", "d8-e8"}
+	tests := []struct {
+		name  string
+		fen   string
+		rules Rules
+		moves []string
+		ply   int
+		want  ResultReason
+	}{
+		{"reaches 150", "4k3/8/8/8/8/8/8/R3K3 w - - 146 80", StandardRules, shuffle, 4, SeventyFiveMoveRule},
+		{"one short", "4k3/8/8/8/8/8/8/R3K3 w - - 145 80", StandardRules, shuffle, 0, InProgress},
+		{"rule off", "4k3/8/8/8/8/8/8/R3K3 w - - 146 80", Rules{Castling: true, EnPassant: true}, shuffle, 0, InProgress},
+		{"pawn move resets", "4k3/8/8/8/8/8/P7/R3K3 w - - 147 80", StandardRules, []string{"a2-a3", "e8-d8", "a1-a2"}, 0, InProgress},
+		// Checkmate on the 150th half-move takes precedence
+		{"mate first", "6k1/5ppp/8/8/8/8/8/R3K3 w - - 149 80", StandardRules, []string{"a1-a8"}, 1, Checkmate},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		rules := tt.rules
+		board.rules = &rules
+		ply, result := playUntil(t, board, tt.moves)
+		if ply != tt.ply || result.Reason != tt.want {
+			t.Errorf("%s: %s after ply %d, want %s after ply %d", tt.name, result.Reason, ply, tt.want, tt.ply)
+		}
+	}
+}
+
+func TestFivefoldRepetition(t *testing.T) {
+	// Each round of knight moves returns to the start position, which
+	// occurs for the fifth time after the fourth round
+	var moves []string
+	for i := 0; i < 5; i++ {
+		moves = append(moves, "g1-f3", "g8-f6", "f3-g1", "f6-g8")
+	}
+	tests := []struct {
+		name  string
+		rules Rules
+		ply   int
+		want  ResultReason
+	}{
+		{"standard", StandardRules, 16, FivefoldRepetition},
+		{"rule off", Rules{Castling: true, EnPassant: true, FiftyMove: true}, 0, InProgress},
+	}
+	for _, tt := range tests {
+		board := NewBoard()
+		rules := tt.rules
+		board.rules = &rules
+		ply, result := playUntil(t, board, moves)
+		if ply != tt.ply || result.Reason != tt.want {
+			t.Errorf("%s: %s after ply %d, want %s after ply %d", tt.name, result.Reason, ply, tt.want, tt.ply)
+		}
+	}
+}
+
+func TestResultMessage(t *testing.T) {
+	tests := []struct {
+		result GameResult
+		want   string
+	}{
+		{GameResult{}, ""},
+		{GameResult{Reason: Checkmate, Winner: Black}, "Checkmate! Black wins!"},
+		{GameResult{Reason: Resignation, Winner: White}, "Black resigns. White wins!"},
+		{GameResult{Reason: Stalemate}, "Stalemate! The game is a draw: the side to move has no legal move but is not in check."},
+		{GameResult{Reason: ThreefoldRepetition}, "Draw by threefold repetition: the same position occurred three times."},
+		{GameResult{Reason: FiftyMoveRule}, "Draw by fifty-move rule: fifty moves by each side without a capture or pawn move."},
+		{GameResult{Reason: InsufficientMaterial}, "Draw by insufficient material: neither side has enough material to checkmate."},
+		{GameResult{Reason: Agreement}, "Draw by agreement: both players agreed."},
+		{GameResult{Reason: FivefoldRepetition}, "Draw by fivefold repetition: the same position occurred five times."},
+		{GameResult{Reason: SeventyFiveMoveRule}, "Draw by seventy-five-move rule: seventy-five moves by each side without a capture or pawn move."},
+	}
+	for _, tt := range tests {
+		if got := tt.result.Message(); got != tt.want {
+			t.Errorf("%s: Message() = %q, want %q", tt.result.Reason, got, tt.want)
+		}
+	}
+}
+
+func TestClaimableDrawAfter(t *testing.T) {
+	// The knights have been out and back once and out again, so bringing
+	// Black's home reaches the start position for the third time
+	shuffle := []string{"g1-f3", "g8-f6", "f3-g1", "f6-g8", "g1-f3", "g8-f6", "f3-g1"}
+	tests := []struct {
+		name   string
+		fen    string
+		played []string
+		rules  Rules
+		move   string
+		want   ResultReason
+	}{
+		{"third repetition", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", shuffle, StandardRules, "f6-g8", ThreefoldRepetition},
+		{"another move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", shuffle, StandardRules, "f6-h5", InProgress},
+		{"repetition rule off", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", shuffle, Rules{FiftyMove: true}, "f6-g8", InProgress},
+		{"fiftieth move", "4k3/4p3/8/8/8/8/8/R3K3 w - - 99 80", nil, StandardRules, "a1-a2", FiftyMoveRule},
+		{"pawn move on the fiftieth", "4k3/4p3/8/8/8/8/8/R3K3 b - - 99 80", nil, StandardRules, "e7-e6", InProgress},
+		{"capture on the fiftieth", "4k3/p7/8/8/8/8/8/R3K3 w - - 99 80", nil, StandardRules, "a1-a7", InProgress},
+		{"one move short", "4k3/4p3/8/8/8/8/8/R3K3 w - - 98 80", nil, StandardRules, "a1-a2", InProgress},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		rules := tt.rules
+		board.rules = &rules
+		playUntil(t, board, tt.played)
+		before, count := board.ToFEN(), board.RepetitionCount()
+
+		parsed, err := ParseCoordinateMove(tt.move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		move, err := board.ResolveMove(parsed, board.ToMove())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := board.ClaimableDrawAfter(move); got != tt.want {
+			t.Errorf("%s: ClaimableDrawAfter(%s) = %s, want %s", tt.name, tt.move, got, tt.want)
+		}
+		// The claim is made with the move still unplayed
+		if board.ToFEN() != before || board.RepetitionCount() != count {
+			t.Errorf("%s: the claim check changed the board to %s", tt.name, board.ToFEN())
+		}
+		claimable := slices.ContainsFunc(board.DrawClaimMoves(), func(m Move) bool { return m.String() == tt.move })
+		if claimable != (tt.want != InProgress) {
+			t.Errorf("%s: %s among DrawClaimMoves = %v", tt.name, tt.move, claimable)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rules switches optional parts of the rules of chess on or off for a game,
+// for casual play and variants. Everything is on in StandardRules.
+type Rules struct {
+	Castling  bool // Kings may castle
+	EnPassant bool // Pawns may capture en passant
+	AutoQueen bool // Promotions typed without a piece become a queen instead of asking
+	FiftyMove bool // The fifty-move claim and the seventy-five-move draw
+	Threefold bool // The threefold repetition claim and the fivefold draw
+}
+
+// StandardRules are the rules of chess, with typed promotions defaulting to
+// a queen
+var StandardRules = Rules{Castling: true, EnPassant: true, AutoQueen: true, FiftyMove: true, Threefold: true}
+
+// ruleNames map the names accepted by ParseRules to the rule they switch
+var ruleNames = map[string]func(*Rules) *bool{
+	"castling":   func(r *Rules) *bool { return &r.Castling },
+	"en-passant": func(r *Rules) *bool { return &r.EnPassant },
+	"auto-queen": func(r *Rules) *bool { return &r.AutoQueen },
+	"fifty-move": func(r *Rules) *bool { return &r.FiftyMove },
+	"threefold":  func(r *Rules) *bool { return &r.Threefold },
+}
+
+// gameRules are the rules new games are played under, set from the command
+// line
+var gameRules = StandardRules
+
+// ParseRules returns the standard rules with the comma-separated rules in
+// spec turned off, e.g. "castling,en-passant"
+func ParseRules(spec string) (Rules, error) {
+	rules := StandardRules
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		rule, ok := ruleNames[name]
+		if !ok {
+			return rules, fmt.Errorf("unknown rule %q (castling, en-passant, auto-queen, fifty-move or threefold)", name)
+		}
+		*rule(&rules) = false
+	}
+	return rules, nil
+}
+
+// Rules returns the rules the board is played under. Boards outside a game,
+// such as those for analysis and puzzles, follow StandardRules.
+func (b *Board) Rules() Rules {
+	if b.rules == nil {
+		return StandardRules
+	}
+	return *b.rules
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEnPassantRule(t *testing.T) {
+	fen := "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1"
+	for _, enPassant := range []bool{true, false} {
+		board, err := BoardFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules := StandardRules
+		rules.EnPassant = enPassant
+		board.rules = &rules
+
+		capture := Move{From: Position{3, 4}, To: Position{2, 3}}
+		generated := slices.ContainsFunc(board.LegalMoves(White), func(m Move) bool {
+			return m.From == capture.From && m.To == capture.To
+		})
+		_, err = board.CheckMove(capture.From, capture.To, White)
+		if generated != enPassant || (err == nil) != enPassant {
+			t.Errorf("en passant rule %v: exd6 generated %v, CheckMove error %v", enPassant, generated, err)
+		}
+
+		want := fen
+		if !enPassant {
+			want = "4k3/8/8/3pP3/8/8/8/4K3 w - - 0 1"
+		}
+		if got := board.ToFEN(); got != want {
+			t.Errorf("en passant rule %v: FEN %s, want %s", enPassant, got, want)
+		}
+	}
+}
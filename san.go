@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SAN returns the move in Standard Algebraic Notation (e.g. "Nf3", "exd5",
+// "O-O", "Qh5#") for display. With -double-check-plus a double check is
+// written "++". It must be called before the move is made on the board.
+func (b *Board) SAN(move Move) string {
+	return b.formatSAN(move, *doubleCheckPlusFlag)
+}
+
+// strictSAN returns the move in SAN as PGN requires it, where any check is
+// "+" and checkmate "#"
+func (b *Board) strictSAN(move Move) string {
+	return b.formatSAN(move, false)
+}
+
+// formatSAN returns the move in SAN, writing a double check as "++" if
+// doublePlus is set
+func (b *Board) formatSAN(move Move, doublePlus bool) string {
+	var san string
+	piece := move.Piece
+
+	switch {
+	case move.IsCastling:
+		san = "O-O"
+		if move.To.Col < move.From.Col {
+			san = "O-O-O"
+		}
+	case piece.Type == Pawn:
+		if move.Captured != nil || move.IsEnPassant {
+			san = string(rune('a'+move.From.Col)) + "x"
+		}
+		san += move.To.String()
+		if move.Promotion != Pawn {
+			san += "=" + string(rune(pieceLetters[move.Promotion]-('a'-'A')))
+		}
+	default:
+		san = string(rune(pieceLetters[piece.Type] - ('a' - 'A')))
+		san += b.disambiguation(move)
+		if move.Captured != nil {
+			san += "x"
+		}
+		san += move.To.String()
+	}
+
+	// Add check or checkmate suffix
+	opponent := 1 - piece.Player
+	b.makeMove(move)
+	if b.IsCheckmate(opponent) {
+		san += "#"
+	} else if doublePlus && len(b.CheckingPieces(opponent)) > 1 {
+		san += "++"
+	} else if b.IsInCheck(opponent) {
+		san += "+"
+	}
+	b.undoMove(move)
+
+	return san
+}
+
+// disambiguation returns the file, rank, or full square needed to tell the
+// moving piece apart from others of the same type that can reach the same square
+func (b *Board) disambiguation(move Move) string {
+	ambiguous, sameFile, sameRank := false, false, false
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			other := b.squares[row][col]
+			if other == nil || other == move.Piece || other.Type != move.Piece.Type || other.Player != move.Piece.Player {
+				continue
+			}
+			if !b.isLegalMove(Position{row, col}, move.To, other.Player) {
+				continue
+			}
+			ambiguous = true
+			if col == move.From.Col {
+				sameFile = true
+			}
+			if row == move.From.Row {
+				sameRank = true
+			}
+		}
+	}
+
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return string(rune('a' + move.From.Col))
+	case !sameRank:
+		return string(rune('8' - move.From.Row))
+	default:
+		return move.From.String()
+	}
+}
+
+// LineSAN formats a sequence of moves starting from the current position
+// as numbered SAN, e.g. "1. e4 e5 2. Nf3" or "5... Nc6 6. Bb5". The board is
+// left unchanged.
+func (b *Board) LineSAN(moves []Move) string {
+	var parts []string
+	for i, move := range moves {
+		number := b.moveCount/2 + 1
+		san := b.SAN(move)
+		if b.ToMove() == White {
+			san = fmt.Sprintf("%d. %s", number, san)
+		} else if i == 0 {
+			san = fmt.Sprintf("%d... %s", number, san)
+		}
+		parts = append(parts, san)
+		b.makeMove(move)
+	}
+	for i := len(moves) - 1; i >= 0; i-- {
+		b.undoMove(moves[i])
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseSAN returns the legal move for the side to move written in Standard
+// Algebraic Notation. It reads the notation itself rather than comparing
+// against SAN, so it also accepts moves written with more disambiguation
+// than needed. Check, mate and annotation suffixes like "+" and "!?" are
+// ignored.
+func (b *Board) ParseSAN(san string) (Move, error) {
+	text := strings.TrimRight(san, "+#!?")
+	if text == "" {
+		return Move{}, fmt.Errorf("empty move")
+	}
+
+	var matches func(Move) bool
+	switch text {
+	case "O-O", "0-0":
+		matches = func(m Move) bool { return m.IsCastling && m.To.Col > m.From.Col }
+	case "O-O-O", "0-0-0":
+		matches = func(m Move) bool { return m.IsCastling && m.To.Col < m.From.Col }
+	default:
+		pt := Pawn
+		if text[0] >= 'A' && text[0] <= 'Z' {
+			var ok bool
+			if pt, ok = pieceTypeFromLetter(text[0] + 'a' - 'A'); !ok || pt == Pawn {
+				return Move{}, fmt.Errorf("invalid piece letter %q in %q", text[0], san)
+			}
+			text = text[1:]
+		}
+		promotion := Pawn
+		if i := strings.IndexByte(text, '='); i >= 0 {
+			var ok bool
+			if len(text) != i+2 {
+				return Move{}, fmt.Errorf("invalid promotion in %q", san)
+			}
+			if promotion, ok = pieceTypeFromLetter(text[i+1] + 'a' - 'A'); !ok {
+				return Move{}, fmt.Errorf("invalid promotion in %q", san)
+			}
+			text = text[:i]
+		}
+		text = strings.Replace(text, "x", "", 1)
+		if len(text) < 2 || len(text) > 4 {
+			return Move{}, fmt.Errorf("invalid move %q", san)
+		}
+		to, err := ParseSquare(text[len(text)-2:])
+		if err != nil {
+			return Move{}, fmt.Errorf("invalid move %q: %v", san, err)
+		}
+		from := text[:len(text)-2]
+		if strings.Trim(from, "abcdefgh12345678") != "" {
+			return Move{}, fmt.Errorf("invalid move %q", san)
+		}
+		matches = func(m Move) bool {
+			if m.Piece.Type != pt || m.To != to || m.Promotion != promotion || m.IsCastling {
+				return false
+			}
+			for i := 0; i < len(from); i++ {
+				c := from[i]
+				if c >= 'a' && c <= 'h' && m.From.Col != int(c-'a') ||
+					c >= '1' && c <= '8' && m.From.Row != 8-int(c-'0') {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	var candidates []Move
+	for _, m := range b.LegalMoves(b.ToMove()) {
+		if matches(m) {
+			candidates = append(candidates, m)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return Move{}, fmt.Errorf("%s is not a legal move for %s", san, b.ToMove())
+	case 1:
+		return candidates[0], nil
+	}
+	return Move{}, fmt.Errorf("%s is ambiguous", san)
+}
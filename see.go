@@ -0,0 +1,117 @@
+package main
+
+import "math"
+
+// Attackers returns the squares of player's pieces that can legally capture
+// on target
+func (b *Board) Attackers(target Position, player Player) []Position {
+	var attackers []Position
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece == nil || piece.Player != player {
+				continue
+			}
+			if b.isLegalMove(Position{row, col}, target, player) {
+				attackers = append(attackers, Position{row, col})
+			}
+		}
+	}
+	return attackers
+}
+
+// exchangeValue is a piece's value when choosing attackers for an exchange;
+// the king always goes last since it can only capture undefended pieces
+func exchangeValue(pt PieceType) int {
+	if pt == King {
+		return math.MaxInt32
+	}
+	return pieceValues[pt]
+}
+
+// SEE performs a Static Exchange Evaluation of side capturing on target: the
+// least valuable attacker captures first, then both sides alternately
+// recapture with their least valuable attacker for as long as it pays. It
+// returns side's net material gain in centipawns, negative when the capture
+// loses material, or 0 if there is nothing side can capture there.
+func (b *Board) SEE(target Position, side Player) int {
+	captured := b.squares[target.Row][target.Col]
+	if captured == nil || captured.Player == side {
+		return 0
+	}
+	return b.exchange(target, side)
+}
+
+// exchange returns side's gain from capturing on target with its least
+// valuable attacker, assuming the opponent only recaptures when it pays
+func (b *Board) exchange(target Position, side Player) int {
+	captured := b.squares[target.Row][target.Col]
+	move, ok := b.leastValuableCapture(target, side)
+	if !ok {
+		return 0
+	}
+
+	b.makeMove(move)
+	gain := pieceValues[captured.Type] - max(0, b.exchange(target, 1-side))
+	b.undoMove(move)
+	return gain
+}
+
+// leastValuableCapture returns side's legal capture on target made with its
+// least valuable piece
+func (b *Board) leastValuableCapture(target Position, side Player) (Move, bool) {
+	var best Move
+	found := false
+	for _, from := range b.Attackers(target, side) {
+		move, err := b.CheckMove(from, target, side)
+		if err != nil {
+			continue
+		}
+		if !found || exchangeValue(move.Piece.Type) < exchangeValue(best.Piece.Type) {
+			best, found = move, true
+		}
+	}
+	return best, found
+}
+
+// Threatened returns the squares of player's pieces, other than the king,
+// that the opponent could capture at a profit, as judged by SEE: attacked
+// and not defended well enough. The board is left unchanged.
+func (b *Board) Threatened(player Player) []Position {
+	var squares []Position
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			piece := b.squares[row][col]
+			if piece == nil || piece.Player != player || piece.Type == King {
+				continue
+			}
+			if b.SEE(Position{row, col}, 1-player) > 0 {
+				squares = append(squares, Position{row, col})
+			}
+		}
+	}
+	return squares
+}
+
+// IsSafeToMoveTo reports whether moving the piece on from to to keeps it
+// safe: the opponent can't win more by capturing it on to than the move
+// itself captured, as judged by SEE. Illegal moves are never safe.
+func (b *Board) IsSafeToMoveTo(from, to Position) bool {
+	piece := b.squares[from.Row][from.Col]
+	if piece == nil {
+		return false
+	}
+	move, err := b.CheckMove(from, to, piece.Player)
+	if err != nil {
+		return false
+	}
+
+	gained := 0
+	if move.Captured != nil {
+		gained = pieceValues[move.Captured.Type]
+	}
+	b.makeMove(move)
+	lost := b.SEE(to, 1-piece.Player)
+	b.undoMove(move)
+	return lost <= gained
+}
@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		name   string
+		fen    string
+		target string
+		want   int
+	}{
+		// The pawn on e6 defends d5, so the knight is lost for a pawn
+		{"knight takes defended pawn", "4k3/8/4p3/3p4/8/2N5/8/4K3 w - - 0 1", "d5", 100 - 320},
+		// Pawn takes pawn and pawn takes back
+		{"pawn takes defended pawn", "4k3/8/4p3/3p4/4P3/8/8/4K3 w - - 0 1", "d5", 0},
+		// The least valuable attacker, the pawn, captures first
+		{"pawn and knight take defended knight", "4k3/8/4p3/3n4/4P3/2N5/8/4K3 w - - 0 1", "d5", 320},
+		{"knight takes undefended rook", "4k3/8/8/3r4/8/2N5/8/4K3 w - - 0 1", "d5", 500},
+		{"king takes undefended pawn", "4k3/8/8/8/8/8/3p4/4K3 w - - 0 1", "d2", 100},
+		{"nothing attacks", "4k3/8/8/3p4/8/8/8/4K3 w - - 0 1", "d5", 0},
+		{"empty square", "4k3/8/8/8/8/2N5/8/4K3 w - - 0 1", "d5", 0},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		target, err := ParseSquare(tt.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := board.SEE(target, White); got != tt.want {
+			t.Errorf("%s: SEE(%s) = %d, want %d", tt.name, tt.target, got, tt.want)
+		}
+		if got := board.ToFEN(); got != tt.fen {
+			t.Errorf("%s: SEE left the board as %s", tt.name, got)
+		}
+	}
+}
+
+func TestIsSafeToMoveTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		from, to string
+		safe     bool
+	}{
+		// The king itself blocks the rook's line to f4 until it steps there
+		{"along the checking line", "4k3/8/8/8/r3K3/8/8/8 w - - 0 1", "e4", "f4", false},
+		{"off the checking line", "4k3/8/8/8/r3K3/8/8/8 w - - 0 1", "e4", "f5", true},
+		{"defended square", "4k3/8/4p3/8/8/2N5/8/6K1 w - - 0 1", "c3", "d5", false},
+		// The e6 pawn is pinned to its king by the rook, so it can't take back
+		{"defended only by a pinned piece", "4k3/8/4p3/8/8/2N5/8/4R1K1 w - - 0 1", "c3", "d5", true},
+		// The pawn takes back a rook worth more than the knight it took
+		{"capture losing the exchange", "4k3/8/4p3/3n4/8/8/8/3R2K1 w - - 0 1", "d1", "d5", false},
+		{"even trade", "4k3/8/4p3/3r4/8/8/8/3R2K1 w - - 0 1", "d1", "d5", true},
+		{"illegal move", "4k3/8/8/8/8/2N5/8/6K1 w - - 0 1", "c3", "c5", false},
+	}
+	for _, tt := range tests {
+		board, err := BoardFromFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		from, err := ParseSquare(tt.from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		to, err := ParseSquare(tt.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := board.IsSafeToMoveTo(from, to); got != tt.safe {
+			t.Errorf("%s: IsSafeToMoveTo(%s, %s) = %v, want %v", tt.name, tt.from, tt.to, got, tt.safe)
+		}
+	}
+}
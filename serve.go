@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+)
+
+// spectatorPage is the live view, reloading itself every two seconds
+const spectatorPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="2">
+<title>terminal_chess</title>
+</head>
+<body>
+<pre style="font-size: 2em">%s</pre>
+<p>%s</p>
+<p><code>%s</code></p>
+</body>
+</html>
+`
+
+// SpectatorServer serves a read-only live view of the game over HTTP. It
+// listens for moves to publish a snapshot of the board, so any number of
+// viewers can read it while the game goes on.
+type SpectatorServer struct {
+	view BoardView
+}
+
+// Serve starts serving spectators on addr in the background, publishing
+// the current position of g first
+func Serve(addr string, g *Game) (*SpectatorServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &SpectatorServer{}
+	s.view.Publish(g.Board)
+	go http.Serve(listener, s)
+	return s, nil
+}
+
+// MoveMade publishes the position after the move
+func (s *SpectatorServer) MoveMade(g *Game, move Move) {
+	s.view.Publish(g.Board)
+}
+
+// MoveUndone publishes the position after a takeback
+func (s *SpectatorServer) MoveUndone(g *Game) {
+	s.view.Publish(g.Board)
+}
+
+// ServeHTTP answers "/" with the HTML view and "/fen" with the FEN alone
+func (s *SpectatorServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "read-only view", http.StatusMethodNotAllowed)
+		return
+	}
+	// Working out the result makes moves, so use a copy per request
+	board := s.view.Load().Snapshot()
+
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		drawn := board.DrawString(RenderOptions{Border: borderStyles["unicode"]})
+		status := fmt.Sprintf("%s to move", board.ToMove())
+		if result := board.Result(); result.IsOver() {
+			status = result.Message()
+		}
+		fmt.Fprintf(w, spectatorPage, html.EscapeString(drawn), html.EscapeString(status), html.EscapeString(board.ToFEN()))
+	case "/fen":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, board.ToFEN())
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import "sync"
+
+// Snapshot returns a deep copy of the board, including its pieces, that
+// stays unaffected as the original game advances
+func (b *Board) Snapshot() *Board {
+	c := *b
+	clones := make(map[*Piece]*Piece)
+	clone := func(p *Piece) *Piece {
+		if p == nil {
+			return nil
+		}
+		if cp, ok := clones[p]; ok {
+			return cp
+		}
+		cp := *p
+		clones[p] = &cp
+		return &cp
+	}
+
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			c.squares[row][col] = clone(b.squares[row][col])
+		}
+	}
+
+	c.lastMove.Piece = clone(b.lastMove.Piece)
+	c.lastMove.Captured = clone(b.lastMove.Captured)
+	// The copy starts its own history; the move before lastMove isn't needed
+	c.lastMove.prevLastMove = nil
+
+	c.positionCounts = make(map[string]int, len(b.positionCounts))
+	for key, n := range b.positionCounts {
+		c.positionCounts[key] = n
+	}
+	return &c
+}
+
+// Successors returns a snapshot of the position after each of player's
+// legal moves, in LegalMoves order. Each is an independent deep copy with
+// the move counted for repetition, so callers can inspect or play on them,
+// even concurrently, without make and undo. The board is left unchanged.
+func (b *Board) Successors(player Player) []*Board {
+	moves := b.LegalMoves(player)
+	successors := make([]*Board, 0, len(moves))
+	for _, move := range moves {
+		b.makeMove(move)
+		next := b.Snapshot()
+		b.undoMove(move)
+		next.recordPosition()
+		successors = append(successors, next)
+	}
+	return successors
+}
+
+// BoardView gives concurrent readers, such as spectators, a consistent
+// position while the game advances. The writer publishes a snapshot after
+// each move and readers only ever see complete positions.
+type BoardView struct {
+	mu    sync.RWMutex
+	board *Board
+}
+
+// Publish stores a snapshot of b for readers
+func (v *BoardView) Publish(b *Board) {
+	snapshot := b.Snapshot()
+	v.mu.Lock()
+	v.board = snapshot
+	v.mu.Unlock()
+}
+
+// Load returns the last published position, or nil if there is none yet.
+// The returned board must not be modified.
+func (v *BoardView) Load() *Board {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.board
+}
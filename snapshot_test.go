@@ -0,0 +1,74 @@
+package main
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestSnapshotIsIndependent(t *testing.T) {
+	board := NewBoard()
+	playUntil(t, board, []string{"e2-e4", "d7-d5"})
+	fen := board.ToFEN()
+	counts := maps.Clone(board.positionCounts)
+	pawn := board.squares[4][4]
+
+	// Play on the snapshot, capturing the pawn, moving a piece for the
+	// first time and repeating positions, then take a move back
+	snapshot := board.Snapshot()
+	playUntil(t, snapshot, []string{"e4-d5", "g8-f6", "f1-b5", "c7-c6", "b5-a4"})
+	snapshot.squares[0][4].HasMoved = true
+	move, err := snapshot.ResolveMove(Move{From: Position{2, 5}, To: Position{3, 3}}, Black)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot.makeMove(move)
+	snapshot.undoMove(move)
+
+	if got := board.ToFEN(); got != fen {
+		t.Errorf("original became %s, want %s", got, fen)
+	}
+	if !maps.Equal(board.positionCounts, counts) {
+		t.Errorf("original position counts became %v, want %v", board.positionCounts, counts)
+	}
+	if board.squares[4][4] != pawn || pawn.Type != Pawn || pawn.Player != White {
+		t.Errorf("original e4 pawn changed to %v", board.squares[4][4])
+	}
+	if board.squares[0][4].HasMoved {
+		t.Error("original black king was marked as moved")
+	}
+}
+
+func TestSuccessorsAreIndependent(t *testing.T) {
+	board := NewBoard()
+	playUntil(t, board, []string{"e2-e4", "d7-d5"})
+	fen := board.ToFEN()
+	counts := maps.Clone(board.positionCounts)
+
+	successors := board.Successors(White)
+	if got, want := len(successors), len(board.LegalMoves(White)); got != want {
+		t.Fatalf("%d successors, want one for each of %d legal moves", got, want)
+	}
+	// Play on from every successor, then change the first one's pieces by
+	// hand as well
+	for _, next := range successors {
+		move := next.LegalMoves(Black)[0]
+		next.makeMove(move)
+		next.recordPosition()
+	}
+	successors[0].squares[7][4].HasMoved = true
+	successors[0].squares[4][4] = nil
+
+	if got := board.ToFEN(); got != fen {
+		t.Errorf("parent became %s, want %s", got, fen)
+	}
+	if !maps.Equal(board.positionCounts, counts) {
+		t.Errorf("parent position counts became %v, want %v", board.positionCounts, counts)
+	}
+	if board.squares[7][4].HasMoved || board.squares[4][4] == nil {
+		t.Error("changing a successor's pieces changed the parent's")
+	}
+	// Nor do siblings share pieces
+	if successors[1].squares[7][4] == successors[0].squares[7][4] {
+		t.Error("two successors share the white king")
+	}
+}
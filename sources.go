@@ -0,0 +1,749 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrQuit is returned by a MoveSource when the player ends the game
+var ErrQuit = errors.New("player quit")
+
+// MoveSource supplies the moves for one side of a game
+type MoveSource interface {
+	NextMove(g *Game) (Move, error)
+}
+
+// MoveListener is implemented by sources that need to see every move
+// played, such as a network peer that mirrors the game
+type MoveListener interface {
+	MoveMade(g *Game, move Move)
+}
+
+// UndoListener is implemented by listeners that keep a record of the game
+// and need to know when a move is taken back
+type UndoListener interface {
+	MoveUndone(g *Game)
+}
+
+// DrawResponder is implemented by sources that can accept a draw offered
+// by the other side
+type DrawResponder interface {
+	AcceptDraw(g *Game) bool
+}
+
+// NewMoveSource creates a move source from a flag value:
+//
+//	human             moves typed on stdin (default)
+//	ai                the engine searching depth plies
+//	random            a random legal move
+//	script:PATH       moves read from a file, one per line
+//	fifo:PATH         moves written to a named pipe by another program
+//	connect:ADDR      moves received from a peer at ADDR
+//	listen:ADDR       moves received from a peer connecting to ADDR
+func NewMoveSource(spec string, player Player, scanner *bufio.Scanner, depth int, games *GameManager) (MoveSource, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "", "human":
+		return &HumanSource{scanner: scanner, games: games, aliases: inputAliases, prompt: *promptFlag, warnThreats: *warnThreatsFlag}, nil
+	case "ai":
+		return &AISource{Depth: depth, Resign: *aiResignFlag}, nil
+	case "random":
+		return &RandomSource{rng: seededRand}, nil
+	case "script":
+		return NewScriptedSource(arg)
+	case "fifo":
+		return NewFIFOSource(arg)
+	case "connect":
+		conn, err := net.Dial("tcp", arg)
+		if err != nil {
+			return nil, err
+		}
+		return NewNetworkSource(conn, player), nil
+	case "listen":
+		listener, err := net.Listen("tcp", arg)
+		if err != nil {
+			return nil, err
+		}
+		defer listener.Close()
+		fmt.Printf("Waiting for opponent on %s...\n", listener.Addr())
+		conn, err := listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		return NewNetworkSource(conn, player), nil
+	}
+	return nil, fmt.Errorf("unknown move source %q", spec)
+}
+
+// inputAliases are the aliases available to human players, set from the
+// command line
+var inputAliases = defaultAliases
+
+// HumanSource reads moves and commands typed on stdin
+type HumanSource struct {
+	scanner *bufio.Scanner
+	games   *GameManager
+	aliases map[string]string
+	prompt  string // Shown before each move; "{player}" is the side to move
+
+	warnThreats bool // List pieces en prise before each move, toggled by 'threats'
+}
+
+func (h *HumanSource) NextMove(g *Game) (Move, error) {
+	player := g.Board.ToMove()
+	if h.warnThreats {
+		h.showThreats(g)
+	}
+	for {
+		// Prompt for move
+		fmt.Printf("\n%s", formatPrompt(h.prompt, player))
+		if !h.scanner.Scan() {
+			return Move{}, io.EOF
+		}
+		moveStr := expandAlias(h.aliases, cleanLine(h.scanner.Text()), player)
+
+		// Handle special commands
+		switch moveStr {
+		case "quit":
+			return Move{}, ErrQuit
+		case "resign":
+			return Move{}, ErrResign
+		case "undo":
+			return Move{}, ErrUndo
+		case "draw":
+			return Move{}, ErrDraw
+		case "help":
+			fmt.Println("\nCommands:")
+			fmt.Println("- Enter moves in the format: e2-e4")
+			fmt.Println("- Or just the destination, e.g. e4, when only one piece can move there")
+			fmt.Println("- With -select, type a piece's square to see its moves, then where it goes")
+			fmt.Println("- Pawns promote to a queen; add a letter to choose another piece: e7-e8n")
+			fmt.Println("- Add a comment to a move with braces: e2-e4 {good central control}")
+			fmt.Println("- 'undo' to take back the last move")
+			fmt.Println("- 'pgn' to show the game in PGN")
+			fmt.Println("- 'history [N]' to page through the last N moves in SAN, or all of them")
+			fmt.Println("- 'try e2-e4 ...' to explore moves, 'end' to return to the game")
+			fmt.Println("- 'replay' to step through the game and explore variations")
+			fmt.Println("- 'pv [depth]' to show the engine's best line")
+			fmt.Println("- 'see e4' to evaluate the exchange if you capture on a square")
+			fmt.Println("- 'grade [N]' to score your legal moves, best first, showing the top N (default 10)")
+			fmt.Println("- 'describe' to read out the board square by square, 'describe short' for pieces only")
+			fmt.Println("- 'diff <fen1> <fen2>' to list the differences between two positions")
+			fmt.Println("- 'threats' to switch listing your pieces that can be won before each move on or off")
+			fmt.Println("- 'status' to list positional features such as passed pawns")
+			fmt.Println("- 'bench' to measure move generation and search speed")
+			fmt.Println("- 'new' to start another game, 'switch <id>' to change game, 'games' to list them")
+			fmt.Println("- 'draw' to claim a draw by repetition or the fifty-move rule, or else offer one")
+			fmt.Println("- 'draw e2-e4' to claim a draw that the move would bring about, without playing on")
+			fmt.Println("- 'resign' to concede the game")
+			fmt.Println("- 'quit' to end the game")
+			fmt.Println("- 'help' to show this help message")
+			h.printAliases()
+			h.pause()
+			continue
+		case "pgn":
+			fmt.Println()
+			fmt.Print(g.PGN())
+			h.pause()
+			continue
+		case "bench":
+			fmt.Println()
+			if err := Bench(); err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			h.pause()
+			continue
+		case "threats":
+			h.warnThreats = !h.warnThreats
+			if h.warnThreats {
+				fmt.Println("Threat warnings on")
+				h.showThreats(g)
+			} else {
+				fmt.Println("Threat warnings off")
+			}
+			continue
+		case "status":
+			fmt.Println()
+			for _, line := range g.Board.PositionalReport() {
+				fmt.Println(line)
+			}
+			h.pause()
+			continue
+		}
+
+		if fields := strings.Fields(moveStr); len(fields) > 0 {
+			switch fields[0] {
+			case "try":
+				h.sandbox(g, fields[1:])
+				continue
+			case "replay":
+				h.replay(g)
+				continue
+			case "pv":
+				h.showPV(g, fields[1:])
+				continue
+			case "see":
+				h.showSEE(g, fields[1:])
+				continue
+			case "grade":
+				h.showGrades(g, fields[1:])
+				continue
+			case "history":
+				h.showHistory(g, fields[1:])
+				continue
+			case "diff":
+				h.showDiff(fields[1:])
+				continue
+			case "draw":
+				// A claim announcing the move that brings about the draw
+				if move, ok := h.drawClaim(g, fields[1:]); ok {
+					return move, ErrDraw
+				}
+				continue
+			case "describe":
+				fmt.Println()
+				occupiedOnly := len(fields) > 1 && fields[1] == "short"
+				for _, line := range g.Board.Describe(occupiedOnly) {
+					fmt.Println(line)
+				}
+				fmt.Printf("%s to move.\n", player)
+				h.pause()
+				continue
+			case "new":
+				h.games.New()
+				return Move{}, ErrGameSwitched
+			case "switch":
+				if err := h.switchGame(fields[1:]); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					h.pause()
+					continue
+				}
+				return Move{}, ErrGameSwitched
+			case "games":
+				fmt.Println()
+				h.games.List()
+				h.pause()
+				continue
+			}
+		}
+
+		// Parse and validate the move
+		notation, comment := SplitComment(moveStr)
+		parsed, err := ParseCoordinateMove(notation)
+		if square, squareErr := ParseSquare(notation); squareErr == nil {
+			if piece := g.Board.squares[square.Row][square.Col]; *selectFlag && piece != nil && piece.Player == player {
+				// Two-step input: the piece first, then where it goes
+				selected, ok := h.selectDestination(g, square)
+				if !ok {
+					continue
+				}
+				parsed, err = selected, nil
+			} else {
+				// Just the destination, e.g. "e4", when only one piece can go there
+				parsed, err = g.Board.MoveToSquare(square, player)
+			}
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			h.pause()
+			continue
+		}
+
+		move, err := g.Board.ResolveMove(parsed, player)
+		if err != nil {
+			if explanation := g.Board.ExplainIllegal(parsed.From, parsed.To, player); *verboseFlag && explanation != "" {
+				err = errors.New(explanation)
+			}
+			fmt.Printf("Error: %v\n", err)
+			h.pause()
+			continue
+		}
+		if move.Promotion != Pawn && parsed.Promotion == Pawn && !g.Rules.AutoQueen {
+			pt, ok := h.choosePromotion()
+			if !ok {
+				continue
+			}
+			move.Promotion = pt
+		}
+		if *warnBlundersFlag && !g.Board.IsSafeToMoveTo(move.From, move.To) && !h.confirm(
+			fmt.Sprintf("Your %s on %s can be won by %s. Play it anyway?", move.Piece.Type, move.To, 1-player)) {
+			continue
+		}
+		move.Comment = comment
+		return move, nil
+	}
+}
+
+// selectDestination shows the legal destinations of the piece on from and
+// asks where to move it. It returns false if the player cancels or the
+// piece can't move.
+func (h *HumanSource) selectDestination(g *Game, from Position) (Move, bool) {
+	piece := g.Board.squares[from.Row][from.Col]
+	var targets []Position
+	for _, move := range g.Board.LegalMovesFrom(from) {
+		if !slices.Contains(targets, move.To) {
+			targets = append(targets, move.To)
+		}
+	}
+	if len(targets) == 0 {
+		fmt.Printf("Error: the %s on %s has no legal moves\n", piece.Type, from)
+		h.pause()
+		return Move{}, false
+	}
+
+	renderOptions.Highlight = targets
+	if *focusFlag {
+		renderOptions.Focus = append([]Position{from}, targets...)
+	}
+	g.Render()
+	renderOptions.Highlight, renderOptions.Focus = nil, nil
+	if *focusFlag && !renderOptions.Color {
+		// Without colors there is nothing to dim, so list the squares instead
+		squares := make([]string, len(targets))
+		for i, pos := range targets {
+			squares[i] = pos.String()
+		}
+		fmt.Printf("\nThe %s on %s can move to: %s\n", piece.Type, from, strings.Join(squares, ", "))
+	}
+	for {
+		fmt.Printf("\nMove the %s on %s to (* marks legal squares, Enter to cancel): ", piece.Type, from)
+		if !h.scanner.Scan() {
+			return Move{}, false
+		}
+		input := strings.ToLower(strings.TrimSpace(h.scanner.Text()))
+		if input == "" || input == "cancel" {
+			g.Render()
+			return Move{}, false
+		}
+
+		// A promotion piece may follow the square, e.g. "e8n"
+		parsed, err := ParseCoordinateMove(from.String() + "-" + input)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
+		}
+		if !slices.Contains(targets, parsed.To) {
+			fmt.Printf("Error: the %s on %s can't move to %s\n", piece.Type, from, parsed.To)
+			continue
+		}
+		return parsed, true
+	}
+}
+
+// sandbox plays hypothetical moves for both sides until "end" on a copy of
+// the game, so the real game is left exactly as it was, even if it is
+// saved on Ctrl-C while the sandbox waits for input
+func (h *HumanSource) sandbox(g *Game, pending []string) {
+	g = g.clone()
+
+	for {
+		for len(pending) > 0 {
+			notation := pending[0]
+			pending = pending[1:]
+			if notation == "end" {
+				return
+			}
+
+			move, err := ParseCoordinateMove(notation)
+			if err == nil {
+				err = g.Play(move)
+			}
+			if err != nil {
+				fmt.Printf("Error: %s: %v\n", notation, err)
+				pending = nil
+				break
+			}
+		}
+
+		fmt.Println()
+		g.Board.Draw()
+		fmt.Printf("\n[try] %s to move, 'end' to return to the game: ", g.Board.ToMove())
+		if !h.scanner.Scan() {
+			return
+		}
+		pending = strings.Fields(h.scanner.Text())
+	}
+}
+
+// showPV prints the engine's principal variation, searching to the depth
+// given as argument or the -depth flag
+func (h *HumanSource) showPV(g *Game, args []string) {
+	depth := *depthFlag
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Printf("Error: invalid depth %q\n", args[0])
+			h.pause()
+			return
+		}
+		depth = n
+	}
+
+	board := g.Board.Snapshot()
+	pv, score, err := board.Search(depth)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	} else {
+		fmt.Printf("\nDepth %d, %s: %s\n", depth, FormatScore(score), board.LineSAN(pv))
+	}
+	h.pause()
+}
+
+// showGrades scores the side to move's legal moves and lists the best of
+// them, as many as the optional argument asks for
+func (h *HumanSource) showGrades(g *Game, args []string) {
+	limit := 10
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Printf("Error: invalid number of moves %q\n", args[0])
+			h.pause()
+			return
+		}
+		limit = n
+	}
+
+	graded := g.Board.GradeMoves(gradeDepth)
+	if len(graded) == 0 {
+		fmt.Println("Error: no legal moves")
+		h.pause()
+		return
+	}
+	fmt.Printf("\nMoves for %s, best first (%d-ply search):\n", g.Board.ToMove(), gradeDepth)
+	for i, gm := range graded {
+		if i == limit {
+			fmt.Printf("... and %d more\n", len(graded)-limit)
+			break
+		}
+		note := ""
+		if graded[0].Score-gm.Score >= blunderMargin {
+			note = "  blunder"
+		}
+		fmt.Printf("%3d. %-8s %s%s\n", i+1, gm.SAN, FormatScore(gm.Score), note)
+	}
+	h.pause()
+}
+
+// showSEE prints the outcome of the exchange if the side to move captures
+// on the square given as argument
+func (h *HumanSource) showSEE(g *Game, args []string) {
+	if len(args) != 1 {
+		fmt.Println("Error: usage: see <square>")
+		h.pause()
+		return
+	}
+	target, err := ParseSquare(args[0])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		h.pause()
+		return
+	}
+
+	player := g.Board.ToMove()
+	attackers := len(g.Board.Attackers(target, player))
+	defenders := len(g.Board.Attackers(target, 1-player))
+	piece := g.Board.squares[target.Row][target.Col]
+	if piece == nil || piece.Player == player || attackers == 0 {
+		fmt.Printf("\n%s cannot capture anything on %s\n", player, target)
+	} else {
+		fmt.Printf("\n%s on %s: %d attacker(s), %d defender(s), exchange %+.2f\n",
+			piece.Type, target, attackers, defenders, float64(g.Board.SEE(target, player))/100)
+	}
+	h.pause()
+}
+
+// showThreats lists the player's pieces the opponent could win by
+// capturing them
+func (h *HumanSource) showThreats(g *Game) {
+	player := g.Board.ToMove()
+	var threats []string
+	for _, pos := range g.Board.Threatened(player) {
+		threats = append(threats, fmt.Sprintf("%s on %s", g.Board.squares[pos.Row][pos.Col].Type, pos))
+	}
+	if len(threats) > 0 {
+		fmt.Printf("\nUnder threat: %s\n", strings.Join(threats, ", "))
+	}
+}
+
+// drawClaim checks a claim that the move in args brings about a draw by
+// threefold repetition or the fifty-move rule, and returns the move if the
+// claim is valid
+func (h *HumanSource) drawClaim(g *Game, args []string) (Move, bool) {
+	player := g.Board.ToMove()
+	var move Move
+	parsed, err := ParseCoordinateMove(strings.Join(args, " "))
+	if err == nil {
+		move, err = g.Board.ResolveMove(parsed, player)
+	}
+	if err == nil && g.Board.ClaimableDrawAfter(move) == InProgress {
+		err = fmt.Errorf("%s would neither repeat the position a third time nor complete fifty moves", g.Board.SAN(move))
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		h.pause()
+		return Move{}, false
+	}
+	return move, true
+}
+
+// showDiff prints the differences between two positions given as FENs
+func (h *HumanSource) showDiff(args []string) {
+	fen1, fen2, err := splitFENs(args)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		h.pause()
+		return
+	}
+	lines, err := DiffFEN(fen1, fen2)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		h.pause()
+		return
+	}
+
+	fmt.Println()
+	if len(lines) == 0 {
+		fmt.Println("The positions are the same")
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	h.pause()
+}
+
+// switchGame makes the game named by the command argument current
+func (h *HumanSource) switchGame(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: switch <id>")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid game ID %q", args[0])
+	}
+	return h.games.Switch(id)
+}
+
+// printAliases lists the input aliases in the help message
+func (h *HumanSource) printAliases() {
+	if len(h.aliases) == 0 {
+		return
+	}
+	names := make([]string, 0, len(h.aliases))
+	for name := range h.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("\nAliases:")
+	for _, name := range names {
+		fmt.Printf("- '%s' for '%s'\n", name, h.aliases[name])
+	}
+}
+
+// confirm asks a yes/no question, defaulting to no
+func (h *HumanSource) confirm(question string) bool {
+	fmt.Printf("%s (y/n): ", question)
+	if !h.scanner.Scan() {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(h.scanner.Text()))
+	return answer == "y" || answer == "yes"
+}
+
+// AcceptDraw asks the player whether to accept the opponent's draw offer
+func (h *HumanSource) AcceptDraw(g *Game) bool {
+	fmt.Println()
+	return h.confirm(fmt.Sprintf("%s offers a draw. Does %s accept?", g.Board.ToMove(), 1-g.Board.ToMove()))
+}
+
+// choosePromotion asks which piece a pawn promotes to. It returns false if
+// the player cancels with an empty line.
+func (h *HumanSource) choosePromotion() (PieceType, bool) {
+	for {
+		fmt.Print("Promote to (q, r, b or n): ")
+		if !h.scanner.Scan() {
+			return Pawn, false
+		}
+		answer := strings.ToLower(strings.TrimSpace(h.scanner.Text()))
+		if answer == "" {
+			return Pawn, false
+		}
+		if pt, ok := pieceTypeFromLetter(answer[0]); ok && len(answer) == 1 && slices.Contains(promotionChoices, pt) {
+			return pt, true
+		}
+	}
+}
+
+func (h *HumanSource) pause() {
+	fmt.Println("Press Enter to continue...")
+	h.scanner.Scan()
+}
+
+// Resignation heuristic: the AI resigns once its search score has been below
+// -resignThreshold for resignMoves moves in a row, or when it is left with
+// a bare king against mating material
+const (
+	resignThreshold = 1000
+	resignMoves     = 3
+)
+
+// drawMargin is the largest search score, in centipawns for the engine,
+// that still counts as roughly equal when it is offered a draw
+const drawMargin = 50
+
+// AISource plays the engine's best move
+type AISource struct {
+	Depth  int
+	Resign bool // Resign hopeless positions instead of playing on
+
+	losingMoves int
+}
+
+// NextMove returns ErrNoLegalMoves without searching when the side to move
+// is checkmated or stalemated. It searches a copy of the board, which an
+// interrupted game can save while the search runs on.
+func (a *AISource) NextMove(g *Game) (Move, error) {
+	board := g.Board.Snapshot()
+	player := board.ToMove()
+	if len(board.LegalMoves(player)) == 0 {
+		return Move{}, ErrNoLegalMoves
+	}
+	fmt.Printf("\n%s is thinking...\n", player)
+
+	pv, score, err := board.Search(a.Depth)
+	if err != nil {
+		return Move{}, err
+	}
+
+	if a.Resign {
+		if score < -resignThreshold {
+			a.losingMoves++
+		} else {
+			a.losingMoves = 0
+		}
+		if a.losingMoves >= resignMoves || (board.HasBareKing(player) && board.HasMatingMaterial(1-player)) {
+			return Move{}, ErrResign
+		}
+	}
+	return pv[0], nil
+}
+
+// AcceptDraw searches the position to its usual depth and accepts a draw
+// offer unless the engine is better by more than drawMargin. The offering
+// side is to move, so the engine's score is the negated search score.
+func (a *AISource) AcceptDraw(g *Game) bool {
+	_, score, err := g.Board.Search(a.Depth)
+	if err != nil {
+		return false
+	}
+	return -score <= drawMargin
+}
+
+// RandomSource plays a uniformly random legal move, or returns
+// ErrNoLegalMoves when there is none
+type RandomSource struct {
+	rng *rand.Rand
+}
+
+func (r *RandomSource) NextMove(g *Game) (Move, error) {
+	moves := g.Board.LegalMoves(g.Board.ToMove())
+	if len(moves) == 0 {
+		return Move{}, ErrNoLegalMoves
+	}
+	return moves[r.rng.Intn(len(moves))], nil
+}
+
+// ScriptedSource plays moves read from a file, one per line in coordinate
+// notation. Blank lines and lines starting with '#' are skipped; comments
+// in braces are ignored.
+type ScriptedSource struct {
+	scanner *bufio.Scanner
+}
+
+func NewScriptedSource(path string) (*ScriptedSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ScriptedSource{scanner: bufio.NewScanner(f)}, nil
+}
+
+func (s *ScriptedSource) NextMove(g *Game) (Move, error) {
+	for s.scanner.Scan() {
+		line := cleanLine(s.scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return parseMoveLine(g.Board, line)
+	}
+	if err := s.scanner.Err(); err != nil {
+		return Move{}, err
+	}
+	return Move{}, fmt.Errorf("script ended")
+}
+
+// NetworkSource receives moves from a peer over a connection and sends it
+// the moves played by the local side. The line protocol carries one move in
+// coordinate notation per line.
+type NetworkSource struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	side    Player // The side played by the peer
+}
+
+func NewNetworkSource(conn net.Conn, side Player) *NetworkSource {
+	return &NetworkSource{conn: conn, scanner: bufio.NewScanner(conn), side: side}
+}
+
+func (n *NetworkSource) NextMove(g *Game) (Move, error) {
+	fmt.Printf("\nWaiting for %s's move from %s...\n", g.Board.ToMove(), n.conn.RemoteAddr())
+	if !n.scanner.Scan() {
+		if err := n.scanner.Err(); err != nil {
+			return Move{}, err
+		}
+		return Move{}, fmt.Errorf("opponent disconnected")
+	}
+	return parseMoveLine(g.Board, n.scanner.Text())
+}
+
+// MoveMade forwards every move not received from the peer itself
+func (n *NetworkSource) MoveMade(g *Game, move Move) {
+	if move.Piece.Player == n.side {
+		return
+	}
+	fmt.Fprintln(n.conn, move)
+}
+
+// byteOrderMark is the UTF-8 byte order mark some Windows editors put at
+// the start of a text file
+const byteOrderMark = "\ufeff"
+
+// cleanLine prepares a line read from a file or typed input for parsing.
+// Files written on Windows may start with a byte order mark, which is
+// dropped, and end lines with "\r\n": the scanner drops the "\r" with the
+// "\n", and a stray one goes with the surrounding whitespace.
+func cleanLine(line string) string {
+	return strings.TrimSpace(strings.TrimPrefix(line, byteOrderMark))
+}
+
+// parseMoveLine parses a move in coordinate notation for the position on b,
+// ignoring comments. Promotions must name their piece, e.g. "e7-e8n".
+func parseMoveLine(b *Board, line string) (Move, error) {
+	move, err := ParseCoordinateMove(line)
+	if err == nil {
+		err = b.requirePromotion(move)
+	}
+	if err != nil {
+		return Move{}, fmt.Errorf("%q: %v", line, err)
+	}
+	move.Comment = ""
+	return move, nil
+}
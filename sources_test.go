@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHumanSourceCleansInput(t *testing.T) {
+	tests := []struct {
+		line string
+		want error
+		move string
+	}{
+		{byteOrderMark + "resign\r", ErrResign, ""},
+		{"  undo \t", ErrUndo, ""},
+		{byteOrderMark + "draw", ErrDraw, ""},
+		{byteOrderMark + " e2-e4 \r", nil, "e2-e4"},
+	}
+	for _, tt := range tests {
+		h := &HumanSource{scanner: bufio.NewScanner(strings.NewReader(tt.line + "\n"))}
+		move, err := h.NextMove(NewGame())
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%q: error %v, want %v", tt.line, err, tt.want)
+			continue
+		}
+		if tt.move != "" && move.String() != tt.move {
+			t.Errorf("%q: move %s, want %s", tt.line, move, tt.move)
+		}
+	}
+}
+
+func TestHumanSourceFlippedBoard(t *testing.T) {
+	// Squares are typed as named, whichever way up the board is drawn
+	t.Cleanup(func() { renderOptions.Flipped = false })
+	tests := []struct{ line, move string }{
+		{"e2-e4", "e2-e4"},
+		{"g1-f3", "g1-f3"},
+		{"e3", "e2-e3"},
+	}
+	for _, flipped := range []bool{false, true} {
+		renderOptions.Flipped = flipped
+		for _, tt := range tests {
+			h := &HumanSource{scanner: bufio.NewScanner(strings.NewReader(tt.line + "\n"))}
+			move, err := h.NextMove(NewGame())
+			if err != nil {
+				t.Errorf("flipped %v: %q: %v", flipped, tt.line, err)
+				continue
+			}
+			if move.String() != tt.move {
+				t.Errorf("flipped %v: %q played %s, want %s", flipped, tt.line, move, tt.move)
+			}
+		}
+	}
+}
+
+func TestScriptedSourceCleansLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "moves.txt")
+	script := byteOrderMark + "e2-e4\r\n \t\r\n  # reply\r\n\te7-e5 \r\n"
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewScriptedSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGame()
+	for _, want := range []string{"e2-e4", "e7-e5"} {
+		move, err := s.NextMove(g)
+		if err != nil {
+			t.Fatalf("%s: %v", want, err)
+		}
+		if move.String() != want {
+			t.Fatalf("script played %s, want %s", move, want)
+		}
+		if err := g.Play(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.NextMove(g); err == nil {
+		t.Error("script did not end")
+	}
+}
+
+func TestSandboxLeavesGame(t *testing.T) {
+	g := NewGame()
+	h := &HumanSource{scanner: bufio.NewScanner(strings.NewReader("try e2-e4 e7-e5\ng1-f3 end\nresign\n"))}
+	if _, err := h.NextMove(g); !errors.Is(err, ErrResign) {
+		t.Fatalf("error %v, want %v", err, ErrResign)
+	}
+	if len(g.Moves) > 0 || g.Board.ToFEN() != NewBoard().ToFEN() {
+		t.Errorf("game moved on to %s", g.Board.ToFEN())
+	}
+}
+
+func TestMoveSourceComments(t *testing.T) {
+	// Typed comments are kept for the PGN; comments in a script are not
+	h := &HumanSource{scanner: bufio.NewScanner(strings.NewReader("e2-e4 {good central control}\n"))}
+	move, err := h.NextMove(NewGame())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if move.Comment != "good central control" {
+		t.Errorf("typed move comment %q, want %q", move.Comment, "good central control")
+	}
+
+	path := filepath.Join(t.TempDir(), "moves.txt")
+	if err := os.WriteFile(path, []byte("e2-e4 {good central control}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewScriptedSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, err = s.NextMove(NewGame())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if move.String() != "e2-e4" || move.Comment != "" {
+		t.Errorf("script played %s {%s}, want e2-e4 without a comment", move, move.Comment)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Game logs
+//
+// A game log is a JSON-lines file: a header with the starting position
+// followed by one record per move played, so that a crashed session can be
+// resumed by replaying the moves:
+//
+//	{"start": "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"}
+//	{"from": "e2", "to": "e4"}
+//	{"from": "e7", "to": "e5", "comment": "symmetrical"}
+
+type logHeader struct {
+	Start string `json:"start"`
+}
+
+type moveRecord struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Promotion string `json:"promotion,omitempty"` // Piece letter, e.g. "n"
+	Comment   string `json:"comment,omitempty"`
+}
+
+func newMoveRecord(move Move) moveRecord {
+	record := moveRecord{From: move.From.String(), To: move.To.String(), Comment: move.Comment}
+	if move.Promotion != Pawn {
+		record.Promotion = string(pieceLetters[move.Promotion])
+	}
+	return record
+}
+
+// GameLog appends the moves of one game to a log file as they are played
+type GameLog struct {
+	game *Game
+	file *os.File
+	enc  *json.Encoder
+
+	// Sync makes each write wait until the log is on disk. Writes always
+	// reach the operating system at once, so an ended or crashed process
+	// loses no moves; Sync also guards against power loss, at some cost in
+	// speed.
+	Sync bool
+}
+
+// CreateGameLog writes the game so far to path, replacing any existing
+// file, and keeps it open to append further moves
+func CreateGameLog(path string, g *Game) (*GameLog, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	l := &GameLog{game: g, file: f, enc: json.NewEncoder(f)}
+	if err := l.enc.Encode(logHeader{Start: g.Start}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	for _, move := range g.Moves {
+		if err := l.enc.Encode(newMoveRecord(move)); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// MoveMade appends a move of the logged game
+func (l *GameLog) MoveMade(g *Game, move Move) {
+	if g != l.game {
+		return
+	}
+	err := l.enc.Encode(newMoveRecord(move))
+	if err == nil {
+		err = l.sync()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write game log: %v\n", err)
+	}
+}
+
+// MoveUndone rewrites the log without the moves taken back
+func (l *GameLog) MoveUndone(g *Game) {
+	if g != l.game {
+		return
+	}
+	if err := l.rewrite(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write game log: %v\n", err)
+	}
+}
+
+// Follow switches the log to g, such as a rematch, replacing the previous
+// game in the file
+func (l *GameLog) Follow(g *Game) error {
+	l.game = g
+	return l.rewrite()
+}
+
+// rewrite writes the logged game to the file in place of its contents
+func (l *GameLog) rewrite() error {
+	err := l.file.Truncate(0)
+	if err == nil {
+		_, err = l.file.Seek(0, 0)
+	}
+	if err == nil {
+		err = l.enc.Encode(logHeader{Start: l.game.Start})
+	}
+	for _, move := range l.game.Moves {
+		if err == nil {
+			err = l.enc.Encode(newMoveRecord(move))
+		}
+	}
+	if err == nil {
+		err = l.sync()
+	}
+	return err
+}
+
+// sync flushes the log to disk if Sync is set
+func (l *GameLog) sync() error {
+	if !l.Sync {
+		return nil
+	}
+	return l.file.Sync()
+}
+
+func (l *GameLog) Close() error {
+	return l.file.Close()
+}
+
+// LoadGameLog reconstructs a game by replaying the moves of a game log. A
+// corrupt or partial last line, as left by a crash mid-write, is ignored, as
+// are blank lines, a byte order mark and "\r\n" line endings.
+func LoadGameLog(path string) (*Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(bytes.TrimPrefix(scanner.Bytes(), []byte(byteOrderMark)))
+		if len(line) > 0 {
+			lines = append(lines, append([]byte(nil), line...))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%s: empty game log", path)
+	}
+
+	var header logHeader
+	if err := json.Unmarshal(lines[0], &header); err != nil {
+		return nil, fmt.Errorf("%s: invalid header: %v", path, err)
+	}
+	g, err := NewGameFromFEN(header.Start)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	for i, line := range lines[1:] {
+		var record moveRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			if i == len(lines)-2 {
+				break
+			}
+			return nil, fmt.Errorf("%s: line %d: %v", path, i+2, err)
+		}
+		from, err := ParseSquare(record.From)
+		if err == nil {
+			var to Position
+			to, err = ParseSquare(record.To)
+			if err == nil {
+				move := Move{From: from, To: to, Comment: record.Comment}
+				if record.Promotion != "" {
+					move.Promotion, _ = pieceTypeFromLetter(record.Promotion[0])
+				}
+				err = g.Play(move)
+			}
+		}
+		if err != nil {
+			return nil, &ReplayError{
+				Path:  path,
+				Line:  i + 2,
+				Move:  record.From + "-" + record.To + record.Promotion,
+				Board: g.Board,
+				Err:   err,
+			}
+		}
+	}
+	return g, nil
+}
+
+// ReplayError reports the first move of a logged or imported game that
+// could not be played, along with the position it was played in. Replaying
+// stops there.
+type ReplayError struct {
+	Path  string
+	Line  int    // Line of the move in a game log
+	Game  int    // Game in a PGN file, counting from 1
+	Ply   int    // Ply of the move in that game, counting from 1
+	Move  string // The move as recorded
+	Board *Board // The position before the move
+	Err   error
+}
+
+func (e *ReplayError) Error() string {
+	where := fmt.Sprintf("line %d", e.Line)
+	if e.Line == 0 {
+		where = fmt.Sprintf("game %d, ply %d", e.Game, e.Ply)
+	}
+	number := e.Board.moveCount/2 + 1
+	dots := "."
+	if e.Board.ToMove() == Black {
+		dots = "..."
+	}
+	return fmt.Sprintf("%s: %s: move %d%s %s: %v (position: %s)", e.Path, where, number, dots, e.Move, e.Err, e.Board.ToFEN())
+}
+
+func (e *ReplayError) Unwrap() error {
+	return e.Err
+}
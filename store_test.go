@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGameLogFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.jsonl")
+	first := NewGame()
+	log, err := CreateGameLog(path, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	play := func(g *Game, notation string) {
+		t.Helper()
+		m, err := ParseCoordinateMove(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Play(m); err != nil {
+			t.Fatalf("%s: %v", notation, err)
+		}
+		log.MoveMade(g, g.Moves[len(g.Moves)-1])
+	}
+	play(first, "e2-e4")
+	play(first, "e7-e5")
+
+	// After a rematch the log holds the new game alone, and ignores the
+	// old one
+	second, err := NewGameFromFEN("4k3/8/8/8/8/8/8/R3K3 w Q - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Follow(second); err != nil {
+		t.Fatal(err)
+	}
+	play(second, "a1-a7")
+	play(first, "g1-f3")
+
+	loaded, err := LoadGameLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Start != second.Start || loaded.Board.ToFEN() != second.Board.ToFEN() {
+		t.Errorf("log resumes at %s from %s, want %s from %s", loaded.Board.ToFEN(), loaded.Start, second.Board.ToFEN(), second.Start)
+	}
+}
+
+// crashDirEnv tells the test binary, run again by TestResumeAfterKill, to
+// play a game logged to that directory and wait to be killed
+const crashDirEnv = "TERMINAL_CHESS_CRASH_DIR"
+
+var crashMoves = []string{"e2-e4", "e7-e5", "g1-f3", "b8-c6", "f1-b5", "a7-a6", "b5-a4"}
+
+func TestResumeAfterKill(t *testing.T) {
+	if dir := os.Getenv(crashDirEnv); dir != "" {
+		playUntilKilled(t, dir)
+		return
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestResumeAfterKill$")
+	cmd.Env = append(os.Environ(), crashDirEnv+"="+dir)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	// Kill the game without warning as soon as the moves are in
+	scanner := bufio.NewScanner(stdout)
+	played := false
+	for !played && scanner.Scan() {
+		played = scanner.Text() == "played"
+	}
+	cmd.Process.Kill()
+	cmd.Wait()
+	if !played {
+		t.Fatal("the game ended before playing its moves")
+	}
+
+	want := playGame(t, NewBoard().ToFEN(), crashMoves)
+	resumed, err := LoadGameLog(filepath.Join(dir, "game.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resumed.Board.ToFEN(); got != want.Board.ToFEN() || len(resumed.Moves) != len(crashMoves) {
+		t.Errorf("resumed %d moves at %s, want %d at %s", len(resumed.Moves), got, len(crashMoves), want.Board.ToFEN())
+	}
+
+	saved, err := os.ReadFile(filepath.Join(dir, "games.pgn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	games, err := ParsePGN(string(saved))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantPGN, _ := ParsePGN(want.PGN()); len(games) != 1 || !slices.Equal(games[0].Moves, wantPGN[0].Moves) {
+		t.Errorf("autosave holds\n%s\nwant the moves of\n%s", saved, want.PGN())
+	}
+}
+
+// playUntilKilled plays crashMoves with the game log and autosave in dir
+// following, as with -db and -autosave, then waits to be killed
+func playUntilKilled(t *testing.T, dir string) {
+	games := NewGameManager(1)
+	g := games.Current()
+	log, err := CreateGameLog(filepath.Join(dir, "game.jsonl"), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	autosave := NewAutosave(games, filepath.Join(dir, "games.pgn"), 1)
+	for _, notation := range crashMoves {
+		m, err := ParseCoordinateMove(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Play(m); err != nil {
+			t.Fatalf("%s: %v", notation, err)
+		}
+		log.MoveMade(g, g.Moves[len(g.Moves)-1])
+		autosave.MoveMade(g, g.Moves[len(g.Moves)-1])
+	}
+	fmt.Println("played")
+	time.Sleep(time.Minute)
+	t.Error("the game wasn't killed")
+}
+
+func TestLoadGameLogWindowsFile(t *testing.T) {
+	// A log copied through a Windows editor gains a byte order mark, "\r\n"
+	// line endings and perhaps a blank line or two
+	path := filepath.Join(t.TempDir(), "game.jsonl")
+	g := NewGame()
+	log, err := CreateGameLog(path, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, notation := range []string{"e2-e4", "e7-e5"} {
+		m, err := ParseCoordinateMove(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Play(m); err != nil {
+			t.Fatalf("%s: %v", notation, err)
+		}
+		log.MoveMade(g, g.Moves[len(g.Moves)-1])
+	}
+	log.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	edited := byteOrderMark + strings.Join(lines[:2], " \r\n") + "\r\n \t\r\n" + strings.Join(lines[2:], "\r\n") + "\r\n\r\n"
+	if err := os.WriteFile(path, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadGameLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Board.ToFEN() != g.Board.ToFEN() {
+		t.Errorf("log resumes at %s, want %s", loaded.Board.ToFEN(), g.Board.ToFEN())
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SVG board geometry in pixels
+const (
+	svgSquare = 45
+	svgMargin = 20 // Room for the coordinates
+)
+
+// SVGString renders the board as a standalone SVG image with caption
+// written underneath, e.g. the move that led to the position
+func (b *Board) SVGString(caption string) string {
+	size := 8*svgSquare + 2*svgMargin
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		size, size+svgMargin, size, size+svgMargin)
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="white"/>`+"\n", size, size+svgMargin)
+
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			x, y := svgMargin+col*svgSquare, svgMargin+row*svgSquare
+			fill := "#f0d9b5"
+			if (row+col)%2 == 1 {
+				fill = "#b58863"
+			}
+			fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", x, y, svgSquare, svgSquare, fill)
+			if piece := b.squares[row][col]; piece != nil {
+				fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="38" text-anchor="middle">%s</text>`+"\n",
+					x+svgSquare/2, y+svgSquare-8, piece)
+			}
+		}
+	}
+
+	// File letters below the board and rank numbers to its left
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="12" text-anchor="middle">%c</text>`+"\n",
+			svgMargin+i*svgSquare+svgSquare/2, svgMargin+8*svgSquare+14, 'a'+i)
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="12" text-anchor="middle">%d</text>`+"\n",
+			svgMargin/2, svgMargin+i*svgSquare+svgSquare/2+4, 8-i)
+	}
+	fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="14" text-anchor="middle">%s</text>`+"\n",
+		size/2, size+svgMargin-4, html.EscapeString(caption))
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// ExportSVG writes the game to dir as one SVG frame per position, from
+// ply-000.svg for the start to one for each move, and an index.html that
+// plays them as an animation. It returns the number of frames written.
+func (g *Game) ExportSVG(dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+	board, err := BoardFromFEN(g.Start)
+	if err != nil {
+		return 0, err
+	}
+
+	var frames []string
+	write := func(caption string) error {
+		name := fmt.Sprintf("ply-%03d.svg", len(frames))
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(board.SVGString(caption)), 0o644); err != nil {
+			return err
+		}
+		frames = append(frames, name)
+		return nil
+	}
+
+	if err := write("Start"); err != nil {
+		return 0, err
+	}
+	for _, m := range g.Moves {
+		move, err := board.ResolveMove(m, board.ToMove())
+		if err != nil {
+			return len(frames), err
+		}
+		dots := "."
+		if board.ToMove() == Black {
+			dots = "..."
+		}
+		caption := fmt.Sprintf("%d%s %s", board.moveCount/2+1, dots, board.strictSAN(move))
+		board.makeMove(move)
+		board.recordPosition()
+		if err := write(caption); err != nil {
+			return len(frames), err
+		}
+	}
+
+	// The page shows the frames in turn, one a second, and starts over
+	quoted := make([]string, len(frames))
+	for i, name := range frames {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	page := fmt.Sprintf(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>terminal_chess game</title></head>
+<body>
+<img id="board" src="%s" alt="chess board">
+<script>
+var frames = [%s], i = 0;
+setInterval(function () {
+  i = (i + 1) %% frames.length;
+  document.getElementById("board").src = frames[i];
+}, 1000);
+</script>
+</body></html>
+`, frames[0], strings.Join(quoted, ", "))
+	return len(frames), os.WriteFile(filepath.Join(dir, "index.html"), []byte(page), 0o644)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportSVG(t *testing.T) {
+	moves := []string{"e2-e4", "e7-e5", "g1-f3", "b8-c6", "f1-b5"}
+	g := playGame(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", moves)
+	dir := filepath.Join(t.TempDir(), "frames")
+	n, err := g.ExportSVG(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(moves)+1 {
+		t.Errorf("wrote %d frames, want %d", n, len(moves)+1)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	want := []string{"index.html"}
+	for i := 0; i <= len(moves); i++ {
+		want = append(want, fmt.Sprintf("ply-%03d.svg", i))
+	}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Errorf("wrote %v, want %v", names, want)
+	}
+
+	// Each frame is captioned with the move that led to it
+	captions := []string{">Start<", ">1. e4<", ">1... e5<", ">2. Nf3<", ">2... Nc6<", ">3. Bb5<"}
+	for i, caption := range captions {
+		data, err := os.ReadFile(filepath.Join(dir, want[i+1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(data), "<svg ") || !strings.Contains(string(data), caption) {
+			t.Errorf("%s: no SVG captioned %s", want[i+1], caption)
+		}
+	}
+	page, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), `"ply-000.svg", "ply-001.svg"`) || !strings.Contains(string(page), `"ply-005.svg"]`) {
+		t.Errorf("index.html doesn't list every frame:\n%s", page)
+	}
+}
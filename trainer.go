@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// RunTrainer quizzes the player on square names: a square is marked on an
+// empty board and has to be named, e.g. "e4". It keeps a running score and
+// average answer time until the player types "quit".
+func RunTrainer(scanner *bufio.Scanner, rng *rand.Rand) {
+	board := &Board{}
+	correct, total := 0, 0
+	var elapsed time.Duration
+
+	for {
+		target := Position{rng.Intn(8), rng.Intn(8)}
+		opts := renderOptions
+		opts.Highlight = []Position{target}
+
+		if *noClearFlag {
+			fmt.Println()
+		} else {
+			ClearScreen()
+		}
+		fmt.Printf("Coordinate trainer: %s\n\n", trainerScore(correct, total, elapsed))
+		fmt.Print(board.DrawString(opts))
+		fmt.Print("\nName the marked square ('quit' to stop): ")
+
+		start := time.Now()
+		if !scanner.Scan() {
+			break
+		}
+		answer := strings.TrimSpace(scanner.Text())
+		if answer == "quit" {
+			break
+		}
+		elapsed += time.Since(start)
+		total++
+
+		if pos, err := ParseSquare(answer); err == nil && pos == target {
+			correct++
+			fmt.Println("Correct!")
+		} else {
+			fmt.Printf("Wrong, that was %s.\n", target)
+		}
+		fmt.Println("Press Enter to continue...")
+		scanner.Scan()
+	}
+	fmt.Printf("\nSession: %s\n", trainerScore(correct, total, elapsed))
+}
+
+// trainerScore formats the accuracy and average answer time
+func trainerScore(correct, total int, elapsed time.Duration) string {
+	if total == 0 {
+		return "no answers yet"
+	}
+	return fmt.Sprintf("%d/%d correct (%d%%), %.1fs per square",
+		correct, total, 100*correct/total, elapsed.Seconds()/float64(total))
+}
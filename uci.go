@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// UCI protocol
+//
+// With -protocol=uci the program runs as a headless engine that chess GUIs
+// and tools speaking the Universal Chess Interface can drive. Only what is
+// needed to play is supported:
+//
+//	uci                                       answered with "id name ..." and "uciok"
+//	isready                                   answered with "readyok"
+//	ucinewgame                                start over from the initial position
+//	position startpos [moves e2e4 e7e5 ...]   the initial position, then the moves
+//	position fen <FEN> [moves ...]            a position in FEN, then the moves
+//	go [depth N]                              search and answer "bestmove e2e4"
+//	quit                                      stop
+//
+// Moves are written as the two squares run together, with the promotion
+// piece appended, which a promotion must name, e.g. "e7e8q". go searches
+// -depth plies unless told otherwise; time controls are ignored. With no legal move to play the
+// answer is "bestmove 0000". Unknown commands are ignored, as UCI asks,
+// and errors are reported on "info string" lines.
+
+// RunUCI serves UCI commands from in until "quit" or EOF, searching depth
+// plies for "go" without a depth of its own
+func RunUCI(in io.Reader, out io.Writer, depth int) error {
+	board := NewBoard()
+	scanner := bufio.NewScanner(in)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "uci":
+			fmt.Fprintln(out, "id name terminal_chess")
+			fmt.Fprintln(out, "id author the terminal_chess authors")
+			fmt.Fprintln(out, "uciok")
+		case "isready":
+			fmt.Fprintln(out, "readyok")
+		case "ucinewgame":
+			board = NewBoard()
+		case "position":
+			b, err := uciPosition(fields[1:])
+			if err != nil {
+				fmt.Fprintf(out, "info string %v\n", err)
+				continue
+			}
+			board = b
+		case "go":
+			uciGo(board, fields[1:], depth, out)
+		case "quit":
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// uciPosition builds the board for the arguments of a "position" command
+func uciPosition(args []string) (*Board, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("position needs startpos or fen")
+	}
+
+	// The moves, if any, follow the position
+	moves := []string{}
+	for i, arg := range args {
+		if arg == "moves" {
+			args, moves = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	var board *Board
+	switch args[0] {
+	case "startpos":
+		board = NewBoard()
+	case "fen":
+		b, err := BoardFromFEN(strings.Join(args[1:], " "))
+		if err != nil {
+			return nil, err
+		}
+		board = b
+	default:
+		return nil, fmt.Errorf("position needs startpos or fen, not %q", args[0])
+	}
+
+	for _, notation := range moves {
+		move, err := ParseUCIMove(notation)
+		if err != nil {
+			return nil, err
+		}
+		err = board.requirePromotion(move)
+		if err == nil {
+			err = board.Apply(move, board.ToMove())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("illegal move %s: %v", notation, err)
+		}
+	}
+	return board, nil
+}
+
+// uciGo searches the position and reports the best move, honouring a
+// "depth N" argument and ignoring the rest
+func uciGo(board *Board, args []string, depth int, out io.Writer) {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] != "depth" {
+			continue
+		}
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n < 1 {
+			fmt.Fprintf(out, "info string invalid depth %q\n", args[i+1])
+			return
+		}
+		depth = n
+	}
+
+	pv, score, err := board.Search(depth)
+	if err != nil {
+		// Checkmated or stalemated: UCI's null move says there is nothing to play
+		fmt.Fprintln(out, "bestmove 0000")
+		return
+	}
+	line := make([]string, len(pv))
+	for i, move := range pv {
+		line[i] = UCIMove(move)
+	}
+	fmt.Fprintf(out, "info depth %d score %s nodes %d pv %s\n", depth, uciScore(score), board.NodesSearched(), strings.Join(line, " "))
+	fmt.Fprintf(out, "bestmove %s\n", line[0])
+}
+
+// uciScore formats a search score as UCI's "cp N" in centipawns or
+// "mate N" in moves, negative when the side to move is getting mated
+func uciScore(score int) string {
+	if abs(score) > mateScore-1000 {
+		moves := (mateScore - abs(score) + 1) / 2
+		if score < 0 {
+			moves = -moves
+		}
+		return fmt.Sprintf("mate %d", moves)
+	}
+	return fmt.Sprintf("cp %d", score)
+}
+
+// ParseUCIMove parses a move in UCI notation, e.g. "e2e4" or "e7e8q"
+func ParseUCIMove(notation string) (Move, error) {
+	if len(notation) != 4 && len(notation) != 5 {
+		return Move{}, fmt.Errorf("invalid move %q (example: e2e4)", notation)
+	}
+	return ParseCoordinateMove(notation[:2] + "-" + notation[2:])
+}
+
+// UCIMove formats a move in UCI notation, e.g. "e2e4" or "e7e8q"
+func UCIMove(move Move) string {
+	s := move.From.String() + move.To.String()
+	if move.Promotion != Pawn {
+		s += string(pieceLetters[move.Promotion])
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// runUCI plays a scripted UCI session and returns the engine's replies,
+// leaving out the search's "info depth" lines, whose node counts vary
+func runUCI(t *testing.T, script ...string) []string {
+	t.Helper()
+	var out strings.Builder
+	if err := RunUCI(strings.NewReader(strings.Join(script, "\n")), &out, 2); err != nil {
+		t.Fatal(err)
+	}
+	var replies []string
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		if !strings.HasPrefix(line, "info depth ") {
+			replies = append(replies, line)
+		}
+	}
+	return replies
+}
+
+func TestUCISession(t *testing.T) {
+	got := runUCI(t,
+		"uci",
+		"isready",
+		"ucinewgame",
+		"position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1",
+		"go depth 2",
+		"position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1 moves a1a8",
+		"go",
+		"position startpos moves e2e5",
+		"position fen 4k3/1P6/8/8/8/8/8/4K3 w - - 0 1 moves b7b8",
+		"position fen 4k3/1P6/8/8/8/8/8/4K3 w - - 0 1 moves b7b8n",
+		"go depth 1",
+		"position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1 moves a1a7",
+		"go depth 0",
+		"bogus",
+		"quit",
+		"isready",
+	)
+	want := []string{
+		"id name terminal_chess",
+		"id author the terminal_chess authors",
+		"uciok",
+		"readyok",
+		"bestmove a1a8",
+		"bestmove 0000",
+		"info string illegal move e2e5: invalid move for ♙",
+		"info string illegal move b7b8: b7-b8 promotes a pawn, add the piece to promote to, e.g. b7-b8q",
+		"bestmove e8d8", // Only legal as the pawn became a knight, not a queen
+		"info string invalid depth \"0\"",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("replies:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestUCIBestMoveIsLegal(t *testing.T) {
+	positions := []string{
+		"startpos",
+		"startpos moves e2e4 e7e5 g1f3 b8c6 f1b5",
+		"fen r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"fen 4k3/8/8/8/8/8/8/4K2R w K - 0 1 moves e1g1",
+	}
+	for _, position := range positions {
+		got := runUCI(t, "position "+position, "go depth 2")
+		if len(got) != 1 || !strings.HasPrefix(got[0], "bestmove ") {
+			t.Errorf("%s: replies %q", position, got)
+			continue
+		}
+		board, err := uciPosition(strings.Fields(position))
+		if err != nil {
+			t.Fatal(err)
+		}
+		move, err := ParseUCIMove(strings.TrimPrefix(got[0], "bestmove "))
+		if err != nil {
+			t.Errorf("%s: %v", position, err)
+			continue
+		}
+		if err := board.Apply(move, board.ToMove()); err != nil {
+			t.Errorf("%s: %s is illegal: %v", position, got[0], err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import "fmt"
+
+// initialCounts is the number of pieces of each type a side starts with
+var initialCounts = map[PieceType]int{
+	Pawn:   8,
+	Rook:   2,
+	Knight: 2,
+	Bishop: 2,
+	Queen:  1,
+	King:   1,
+}
+
+// ValidatePosition rejects positions that cannot arise in a legal game, such
+// as an imported FEN or an edited board, naming the first violation found
+func (b *Board) ValidatePosition(toMove Player) error {
+	for _, player := range []Player{White, Black} {
+		counts := make(map[PieceType]int)
+		for row := 0; row < 8; row++ {
+			for col := 0; col < 8; col++ {
+				piece := b.squares[row][col]
+				if piece == nil || piece.Player != player {
+					continue
+				}
+				counts[piece.Type]++
+
+				if piece.Type == Pawn && (row == 0 || row == 7) {
+					return fmt.Errorf("%s pawn on %s cannot stand on the first or last rank", player, Position{row, col})
+				}
+			}
+		}
+
+		// Castling rights need the king and rook on their starting squares
+		row := 7
+		if player == Black {
+			row = 0
+		}
+		for _, kingSide := range []bool{true, false} {
+			if !b.CanCastle(player, kingSide) {
+				continue
+			}
+			rookCol := 7
+			if !kingSide {
+				rookCol = 0
+			}
+			king, rook := b.squares[row][4], b.squares[row][rookCol]
+			if king == nil || king.Type != King || king.Player != player ||
+				rook == nil || rook.Type != Rook || rook.Player != player {
+				return fmt.Errorf("%s cannot have castling rights without king and rook on their starting squares", player)
+			}
+		}
+
+		if counts[King] != 1 {
+			return fmt.Errorf("%s must have exactly one king, found %d", player, counts[King])
+		}
+		if counts[Pawn] > 8 {
+			return fmt.Errorf("%s has %d pawns, more than 8", player, counts[Pawn])
+		}
+
+		// Every piece beyond the initial set must come from a promoted pawn
+		promoted := 0
+		for _, pt := range []PieceType{Queen, Rook, Bishop, Knight} {
+			promoted += max(0, counts[pt]-initialCounts[pt])
+		}
+		if promoted > 8-counts[Pawn] {
+			return fmt.Errorf("%s has %d promoted pieces but only %d missing pawns", player, promoted, 8-counts[Pawn])
+		}
+	}
+
+	if b.IsInCheck(White) && b.IsInCheck(Black) {
+		return fmt.Errorf("both kings are in check")
+	}
+	if b.IsInCheck(1 - toMove) {
+		return fmt.Errorf("%s is in check but it is %s's turn", 1-toMove, toMove)
+	}
+
+	return nil
+}
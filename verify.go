@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// VerifyPGN replays every game in a PGN file and checks that the SAN this
+// program generates for each move is exactly the token written in the file,
+// apart from annotations like "!?". Files from other programs make a good
+// test of SAN disambiguation and check suffixes. It reports the number of
+// moves checked to w and returns an error at the first move that can't be
+// played, a ReplayError, or is written differently.
+func VerifyPGN(path string, w io.Writer) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	games, err := ParsePGN(string(data))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	moves := 0
+	for i, game := range games {
+		board := NewBoard()
+		if fen, ok := game.Tags["FEN"]; ok {
+			if board, err = BoardFromFEN(fen); err != nil {
+				return fmt.Errorf("%s: game %d: %v", path, i+1, err)
+			}
+		}
+
+		for ply, token := range game.Moves {
+			label := fmt.Sprintf("%d.", board.moveCount/2+1)
+			if board.ToMove() == Black {
+				label += ".."
+			}
+			move, err := board.ParseSAN(token)
+			if err != nil {
+				return &ReplayError{Path: path, Game: i + 1, Ply: ply + 1, Move: token, Board: board, Err: err}
+			}
+			want := strings.TrimRight(token, "!?")
+			if san := board.strictSAN(move); san != want {
+				return fmt.Errorf("%s: game %d, ply %d (%s): the PGN has %s, generated %s", path, i+1, ply+1, label, want, san)
+			}
+			board.makeMove(move)
+			board.recordPosition()
+			moves++
+		}
+	}
+
+	plural := "s"
+	if len(games) == 1 {
+		plural = ""
+	}
+	fmt.Fprintf(w, "%s: %d game%s, %d moves verified\n", path, len(games), plural, moves)
+	return nil
+}
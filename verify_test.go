@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyPGNReplayError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "games.pgn")
+	pgn := `[Event "first"]
+
+1. e4 e5 2. Nf3 Nc6 1-0
+
+[Event "second"]
+
+1. d4 d5 2. c4 e6 3. Ke3 Nf6 *
+`
+	if err := os.WriteFile(path, []byte(pgn), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := VerifyPGN(path, io.Discard)
+	var replayErr *ReplayError
+	if !errors.As(err, &replayErr) {
+		t.Fatalf("VerifyPGN = %v, want a ReplayError", err)
+	}
+	if replayErr.Game != 2 || replayErr.Ply != 5 || replayErr.Move != "Ke3" {
+		t.Errorf("stopped at game %d, ply %d, move %s, want game 2, ply 5, move Ke3", replayErr.Game, replayErr.Ply, replayErr.Move)
+	}
+	if got, want := replayErr.Board.ToFEN(), "rnbqkbnr/ppp2ppp/4p3/3p4/2PP4/8/PP2PPPP/RNBQKBNR w KQkq - 0 3"; got != want {
+		t.Errorf("position %s, want %s", got, want)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "game 2, ply 5: move 3. Ke3") {
+		t.Errorf("message %q doesn't name the move", msg)
+	}
+}
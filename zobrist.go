@@ -0,0 +1,54 @@
+package main
+
+import "math/rand"
+
+// Zobrist keys, generated from a fixed seed so hashes are stable across runs
+var (
+	zobristPieces    [2][6][8][8]uint64 // Indexed by player, piece type, row, col
+	zobristBlack     uint64
+	zobristCastling  [16]uint64
+	zobristEnPassant [8]uint64
+)
+
+func init() {
+	rng := rand.New(rand.NewSource(20240101))
+	for p := range zobristPieces {
+		for t := range zobristPieces[p] {
+			for row := 0; row < 8; row++ {
+				for col := 0; col < 8; col++ {
+					zobristPieces[p][t][row][col] = rng.Uint64()
+				}
+			}
+		}
+	}
+	zobristBlack = rng.Uint64()
+	for i := range zobristCastling {
+		zobristCastling[i] = rng.Uint64()
+	}
+	for i := range zobristEnPassant {
+		zobristEnPassant[i] = rng.Uint64()
+	}
+}
+
+// Hash returns the Zobrist hash of the position: piece placement, side to
+// move, castling rights and en passant file, the same fields as PositionKey.
+// Like PositionKey it leaves out the move counters, and the en passant file
+// when no capture is possible.
+func (b *Board) Hash() uint64 {
+	var h uint64
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			if piece := b.squares[row][col]; piece != nil {
+				h ^= zobristPieces[piece.Player][piece.Type][row][col]
+			}
+		}
+	}
+	if b.ToMove() == Black {
+		h ^= zobristBlack
+	}
+	h ^= zobristCastling[b.castling]
+	if ep := b.enPassantFEN(); ep != "-" {
+		h ^= zobristEnPassant[ep[0]-'a']
+	}
+	return h
+}